@@ -0,0 +1,64 @@
+package main
+
+// Langton's Ant is a single ant walking over a board of cells which are all dead to begin with. Every generation the
+// ant turns right if it stands on a dead cell and left if it stands on a live one, flips the cell, and moves forward
+// one cell. After about ten thousand generations of chaos it settles into building a diagonal "highway" forever.
+
+// The color the ant is drawn in, on top of the cell it stands on.
+var antColor = [3]float32{1, 0.25, 0.25}
+
+// The directions the ant can face in clockwise order, so turning right adds one and turning left subtracts one.
+var antDirections = [4][2]int{
+	{0, 1},  // up
+	{1, 0},  // right
+	{0, -1}, // down
+	{-1, 0}, // left
+}
+
+// ant is where Langton's Ant is on the board and which way it is facing, an index into antDirections.
+type ant struct {
+	x, y      int
+	direction int
+
+	// lost is set once the ant walks off the edge of a board which doesn't wrap, after which it stays put.
+	lost bool
+}
+
+// newAnt returns an ant in the middle of the board, facing up.
+func newAnt(cells [][]*cell) *ant {
+	return &ant{x: len(cells) / 2, y: len(cells[0]) / 2}
+}
+
+// step turns the ant, flips the cell it stands on and moves it forward, counting the flipped cell as a birth or death.
+// It reports whether a cell was flipped, which is always the case until the ant is lost.
+func (a *ant) step(b *Board) bool {
+	b.births, b.deaths = 0, 0
+	if a.lost {
+		return false
+	}
+
+	c := b.cells[a.x][a.y]
+	if c.alive {
+		a.direction = (a.direction + 3) % 4
+		b.deaths++
+	} else {
+		a.direction = (a.direction + 1) % 4
+		b.births++
+	}
+	c.alive = !c.alive
+	c.aliveNext = c.alive
+	b.liveStale = true
+
+	x, y := a.x+antDirections[a.direction][0], a.y+antDirections[a.direction][1]
+	next := b.at(x, y)
+	if next == nil {
+		a.lost = true
+		return true
+	}
+	// Crossing a flipped edge of a Klein bottle turns the board, and so the ant, upside down.
+	if b.klein && (x < 0 || x >= len(b.cells)) {
+		a.direction = (6 - a.direction) % 4
+	}
+	a.x, a.y = next.x, next.y
+	return true
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// The renderers -bench-render compares, in the order they are run.
+var benchRenderers = []string{"gl", "points", "terminal"}
+
+// benchRender draws -bench-render frames with each renderer in turn and prints how long a frame took on average.
+// Every renderer is given a board seeded the same way, which goes through the same generations, so they all draw the
+// same frames. Only the Draw calls are timed, not the generations in between.
+func benchRender() {
+	r, err := newRule(paneRules()[0])
+	if err != nil {
+		panic(err)
+	}
+	seed := newSeed()
+
+	averages := make([]time.Duration, len(benchRenderers))
+	for i, name := range benchRenderers {
+		cfg.Renderer = name
+		renderer := newRenderer()
+		renderer.Init()
+
+		board := newSeededBoard(r, seed)
+		var total time.Duration
+		for frame := 0; frame < cfg.BenchRender; frame++ {
+			start := time.Now()
+			renderer.Draw(board)
+			total += time.Since(start)
+			board.Step()
+		}
+		renderer.Close()
+
+		averages[i] = total / time.Duration(cfg.BenchRender)
+	}
+
+	// The terminal renderer draws over the whole terminal, so the results are only printed once it is done.
+	for i, name := range benchRenderers {
+		fmt.Printf("%-8s %d frames, %v per frame\n", name, cfg.BenchRender, averages[i])
+	}
+}
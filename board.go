@@ -0,0 +1,630 @@
+package main
+
+import (
+	"log"
+	"math/rand"
+	"time"
+)
+
+// Board is the game board, holding the cells and the number of generations they have gone through.
+// It doesn't know anything about OpenGL, so it can be simulated without opening a window.
+type Board struct {
+	cells      [][]*cell
+	generation int
+	rule       Rule
+
+	// The random number generator the cells were seeded with, and its seed.
+	rng  *rand.Rand
+	seed int64
+
+	// wrap makes the board a torus, where cells on an edge neighbor the cells on the opposite edge.
+	// Otherwise everything beyond the edges is dead.
+	wrap bool
+	// klein turns the torus into a Klein bottle: crossing the left or right edge also flips the board upside down,
+	// so a cell at the top of one edge neighbors the bottom of the other.
+	klein bool
+	// wrapAxis limits wrapping to one axis, making the board a cylinder: "x" only wraps the left and right edges around
+	// and "y" only the top and bottom ones. Otherwise both do.
+	wrapAxis string
+	// aliveEdges are the edges with live cells beyond them rather than dead ones when they don't wrap, indexed by
+	// edgeTop and the rest, see -edges.
+	aliveEdges [4]bool
+	// noise is the chance of each cell flipping to the opposite of what the rule says every generation, mutating the
+	// board as it goes. It starts out as the -noise and can be switched off and on again while the game runs.
+	noise float64
+
+	// The number of cells which came to life and died in the last generation.
+	births int
+	deaths int
+	// Whether no cell changed in the last generation, which isn't always enough to have settled under a B0 rule.
+	unchanged bool
+
+	// The cells which are alive or dying, see liveCells. live is stale when the cells have been changed outside of
+	// Step, and is only worked out again once it is needed.
+	live      []*cell
+	liveStale bool
+	// The number of live cells, counted along with live, see Population.
+	alive int
+
+	// Langton's Ant, when running -automaton ant, which replaces the rule in deciding what happens to the cells.
+	ant *ant
+
+	// The cells of the spaceships found in the last generation, see detectSpaceships.
+	spaceships map[*cell]bool
+	// The cells which just came to life across an edge of the board, which aren't drawn, see detectWrapped.
+	wrapped map[*cell]bool
+
+	// Called after every generation, see OnGeneration.
+	observers []func(b *Board, generation, population int)
+	// Counts the live neighbors of each cell in place of the neighbors linked to it, see SetNeighbors.
+	neighborFunc NeighborFunc
+}
+
+// NeighborFunc counts the live neighbors of the cell at (x, y) on the board, for the rule to decide the next state of
+// the cell by. It is called for every cell of the board each generation, before any of them change, and can read the
+// board with Alive and Dimensions but mustn't change it. It is given every x and y on the board and nothing else, and
+// has to handle the edges of the board itself, whether by wrapping around them, treating what lies beyond them as
+// dead, or anything else. Under a Generations rule the count should only include cells which are alive, not dying.
+type NeighborFunc func(b *Board, x, y int) int
+
+type cell struct {
+	// A drawable is a square Vertex Array Object.
+	drawable uint32
+
+	alive     bool
+	aliveNext bool
+
+	// The number of generations the cell has been dying for under a Generations rule, 0 when it isn't dying.
+	dying     uint8
+	dyingNext uint8
+
+	// The QuadLife color of the cell, from 0 to quadLifeColors-1. It only means anything while the cell is alive.
+	color uint8
+
+	// The cells surrounding this one, see linkNeighbors.
+	neighbors [8]*cell
+
+	x int
+	y int
+}
+
+// newBoard returns a board of randomly seeded cells following the -rule, the first one when there are several. With
+// -load, it is loaded with the board saved to the file instead, rule and all.
+func newBoard() *Board {
+	r, err := newRule(paneRules()[0])
+	if err != nil {
+		panic(err)
+	}
+
+	b := newSeededBoard(r, newSeed())
+	if cfg.Load != "" {
+		if err := b.loadState(cfg.Load); err != nil {
+			log.Fatalf("-load: %v", err)
+		}
+		logInfo("Loaded generation", b.generation, "of", b.rule, "from", cfg.Load)
+	}
+	return b
+}
+
+// newSeededBoard returns a board of cells seeded from seed, following the rule.
+func newSeededBoard(r Rule, seed int64) *Board {
+	rng := rand.New(rand.NewSource(seed))
+	b := &Board{cells: makeCells(rng), rule: r, rng: rng, seed: seed, wrap: cfg.Boundary != "fixed", klein: cfg.Boundary == "klein", wrapAxis: cfg.Wrap, noise: cfg.Noise, liveStale: true}
+	if cfg.Edges != "" {
+		sides, err := parseEdges(cfg.Edges)
+		if err != nil {
+			panic(err)
+		}
+		b.setEdges(sides)
+	}
+	if cfg.Automaton == "ant" {
+		b.ant = newAnt(b.cells)
+	}
+	b.linkNeighbors()
+	return b
+}
+
+// SeededBoard returns a board of rows by cols cells following Conway's rule, where each cell is alive with probability
+// density, wrapping around its edges. The cells are drawn from a random number generator of their own seeded with seed,
+// without reading the command line or the global random number generator, and without OpenGL. Identical inputs
+// always yield identical boards, which evolve identically.
+func SeededBoard(rows, cols int, density float64, seed int64) *Board {
+	rng := rand.New(rand.NewSource(seed))
+	cells := make([][]*cell, cols)
+	for x := range cells {
+		cells[x] = make([]*cell, rows)
+		for y := range cells[x] {
+			c := newCell(x, y)
+			c.alive = rng.Float64() < density
+			c.aliveNext = c.alive
+			cells[x][y] = c
+		}
+	}
+
+	b := &Board{cells: cells, rule: Conway{}, rng: rng, seed: seed, wrap: true, liveStale: true}
+	b.linkNeighbors()
+	return b
+}
+
+// newSeed returns the -seed or, when none was given, the current time, giving each game a unique starting state. With
+// a -seed-bank, it returns the next seed in the bank instead.
+func newSeed() int64 {
+	if cfg.SeedBank != "" {
+		return bankSeed()
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logVerbose("Seed", seed)
+
+	return seed
+}
+
+// reset seeds the board with a new starting state from seed. The cells are reused, along with their neighbor links and
+// drawables, so resetting doesn't allocate anything.
+func (b *Board) reset(seed int64) {
+	b.seed = seed
+	b.rng.Seed(b.seed)
+	seedCells(b.cells, b.rng)
+	b.liveStale = true
+	if b.ant != nil {
+		b.ant = newAnt(b.cells)
+	}
+	b.generation = 0
+	b.births, b.deaths = 0, 0
+	b.unchanged = false
+	b.spaceships = nil
+	b.wrapped = nil
+}
+
+// invert kills every live cell and brings every other cell to life, leaving the complement of the board.
+func (b *Board) invert() {
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			c.alive = !c.alive
+			c.aliveNext = c.alive
+			c.dying, c.dyingNext = 0, 0
+		}
+	}
+	b.liveStale = true
+}
+
+// clear kills every cell on the board and starts its generations over from 0, leaving an empty board to draw on.
+func (b *Board) clear() {
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			c.alive, c.aliveNext = false, false
+			c.dying, c.dyingNext = 0, 0
+			c.color = 0
+		}
+	}
+	b.liveStale = true
+	b.generation = 0
+	b.births, b.deaths = 0, 0
+	b.unchanged = false
+	b.spaceships = nil
+	b.wrapped = nil
+}
+
+// setWrap switches the board between wrapping around its edges and having dead cells beyond them.
+func (b *Board) setWrap(wrap bool) {
+	b.wrap = wrap
+	b.linkNeighbors()
+}
+
+// wraps reports whether the board wraps around its left and right edges, and whether it wraps around its top and
+// bottom edges.
+func (b *Board) wraps() (bool, bool) {
+	return b.wrap && b.wrapAxis != "y", b.wrap && b.wrapAxis != "x"
+}
+
+// linkNeighbors links every cell to its neighbors according to the board's boundary.
+func (b *Board) linkNeighbors() {
+	wrapX, wrapY := b.wraps()
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			c.linkNeighbors(b.cells, wrapX, wrapY, b.klein, b.aliveEdges)
+		}
+	}
+}
+
+// Step advances the board by one generation.
+//
+// Each cell must determine its next state based on the current state of the board, so first every cell works out
+// its next state while the current one is left alone, and only then are the next states applied to the whole board.
+// Langton's Ant only changes the cell beneath it, so it is left to the ant instead.
+//
+// Step reports whether any cell changed state. When none did, the board has settled into a still life and every
+// generation from now on will be the same.
+func (b *Board) Step() bool {
+	var changed bool
+	if b.ant != nil {
+		changed = b.ant.step(b)
+	} else {
+		changed = b.stepCells()
+	}
+
+	b.generation++
+
+	if len(b.observers) > 0 {
+		population := b.Population()
+		for _, observe := range b.observers {
+			observe(b, b.generation, population)
+		}
+	}
+
+	return changed
+}
+
+// stepCells works out the next generation of every cell following the rule, and reports whether any cell changed.
+func (b *Board) stepCells() bool {
+	if cfg.Verify {
+		if err := b.verifyOrder(); err != nil {
+			panic(err)
+		}
+	}
+
+	r := b.rule
+	if alternating, ok := r.(b0Rule); ok {
+		r = alternating.at(b.generation)
+	}
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			c.checkState(r, b.countNeighbors(c))
+		}
+	}
+	b.births, b.deaths = 0, 0
+	b.live = b.live[:0]
+	b.alive = 0
+	// Dying cells change without being born or dying, moving on to their next dying state.
+	var dyingChanged bool
+	frozen := cfg.FrozenBorder == "dead" || cfg.FrozenBorder == "alive"
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			if b.noise > 0 && b.rng.Float64() < b.noise {
+				c.aliveNext, c.dyingNext = !c.aliveNext, 0
+			}
+			// A frozen border stays as it is whatever the rule says, walling in the rest of the board.
+			if frozen && b.onBorder(c) {
+				c.aliveNext, c.dyingNext = c.alive, c.dying
+			}
+			if c.aliveNext && !c.alive {
+				b.births++
+			} else if c.alive && !c.aliveNext {
+				b.deaths++
+			}
+			if c.dying != c.dyingNext {
+				dyingChanged = true
+			}
+			c.alive = c.aliveNext
+			c.dying = c.dyingNext
+			if c.state() > 0 {
+				b.live = append(b.live, c)
+			}
+			if c.alive {
+				b.alive++
+			}
+		}
+	}
+	b.liveStale = false
+
+	changed := b.births > 0 || b.deaths > 0 || dyingChanged
+	if alternating, ok := b.rule.(b0Rule); ok && alternating.even != alternating.odd {
+		// The board is stepped by two rules in turn, so it has only settled once neither of them changes it.
+		settled := !changed && b.unchanged
+		b.unchanged = !changed
+		return !settled
+	}
+	return changed
+}
+
+// onBorder reports whether the cell is in the outermost ring of cells of the board.
+func (b *Board) onBorder(c *cell) bool {
+	return c.x == 0 || c.y == 0 || c.x == len(b.cells)-1 || c.y == len(b.cells[0])-1
+}
+
+// liveCells returns the cells which are alive or dying, the only ones with anything to draw. Step keeps track of them
+// as it goes, so they only have to be searched for after the cells have been changed some other way.
+func (b *Board) liveCells() []*cell {
+	if b.liveStale {
+		b.live = b.live[:0]
+		b.alive = 0
+		for x := range b.cells {
+			for _, c := range b.cells[x] {
+				if c.state() > 0 {
+					b.live = append(b.live, c)
+				}
+				if c.alive {
+					b.alive++
+				}
+			}
+		}
+		b.liveStale = false
+	}
+
+	return b.live
+}
+
+// OnGeneration registers f to be called with the board, its generation and its population after every generation,
+// in the order the functions were registered. This lets features like statistics keep track of the game without the
+// game loop having to know about them.
+func (b *Board) OnGeneration(f func(b *Board, generation, population int)) {
+	b.observers = append(b.observers, f)
+}
+
+// Population returns the number of live cells on the board. Step counts them as it commits each generation, so they
+// only have to be counted again after the cells have been changed some other way, see liveCells.
+func (b *Board) Population() int {
+	b.liveCells()
+	return b.alive
+}
+
+// Generation returns the number of generations the board has gone through since it was seeded.
+func (b *Board) Generation() int {
+	return b.generation
+}
+
+// SetNeighbors makes the board count the live neighbors of its cells with f from the next generation on, for trying
+// out neighborhoods other than the eight cells around each cell. nil goes back to MooreNeighbors.
+func (b *Board) SetNeighbors(f NeighborFunc) {
+	b.neighborFunc = f
+}
+
+// MooreNeighbors is the NeighborFunc boards use unless SetNeighbors is given another, which counts the live cells of
+// the eight around the cell at (x, y), wrapping around the edges of the board as the -boundary has it.
+func MooreNeighbors(b *Board, x, y int) int {
+	return b.cells[x][y].liveNeighbors()
+}
+
+// countNeighbors returns the number of live neighbors of the cell, counted by the NeighborFunc of the board if it has
+// one. Otherwise they are counted straight from the neighbors linked to the cell, as MooreNeighbors does, without the
+// cost of calling through a function value for every cell.
+func (b *Board) countNeighbors(c *cell) int {
+	if b.neighborFunc != nil {
+		return b.neighborFunc(b, c.x, c.y)
+	}
+	return c.liveNeighbors()
+}
+
+// Alive reports whether the cell at (x, y) is alive, which it never is outside of the board.
+func (b *Board) Alive(x, y int) bool {
+	if x < 0 || x >= len(b.cells) || y < 0 || y >= len(b.cells[x]) {
+		return false
+	}
+	return b.cells[x][y].alive
+}
+
+// Dimensions returns the number of rows and columns of cells on the board.
+func (b *Board) Dimensions() (rows, cols int) {
+	return len(b.cells[0]), len(b.cells)
+}
+
+// status returns the status of the board to show while the game runs.
+func (b *Board) status() status {
+	return status{generation: b.generation, population: b.Population(), births: b.births, deaths: b.deaths}
+}
+
+// Hash returns a hash of the live cells on the board, visiting them row by row from the bottom left, like the board
+// is drawn. It is the 64 bit FNV-1a hash of one byte per cell, 1 for a live cell and 0 for any other, worked out in
+// place so that it allocates nothing. Two boards with the same dimensions and cells always have the same hash, on any
+// run and any platform, which makes it easy to check that a run ended up in exactly the state it should.
+func (b *Board) Hash() uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+
+	h := uint64(offset64)
+	for y := range b.cells[0] {
+		for x := range b.cells {
+			if b.cells[x][y].alive {
+				h ^= 1
+			}
+			h *= prime64
+		}
+	}
+
+	return h
+}
+
+// makeCells creates a board of columns by rows cells, indexed as cells[x][y], and seeds them from rng.
+func makeCells(rng *rand.Rand) [][]*cell {
+	cells := make([][]*cell, cfg.Columns, cfg.Columns)
+	for x := 0; x < cfg.Columns; x++ {
+		for y := 0; y < cfg.Rows; y++ {
+			cells[x] = append(cells[x], newCell(x, y))
+		}
+	}
+
+	seedCells(cells, rng)
+	return cells
+}
+
+// seedCells gives the cells their starting state, drawing random numbers from rng. Every cell is set, so cells which
+// have been played with before can be seeded again instead of allocating new ones.
+func seedCells(cells [][]*cell, rng *rand.Rand) {
+	chance, ok := seedPatterns[cfg.SeedPattern]
+	if !ok {
+		chance = seedPatterns["uniform"]
+	}
+	for x := range cells {
+		for _, c := range cells[x] {
+			c.alive, c.aliveNext = false, false
+			c.dying, c.dyingNext = 0, 0
+			c.color = 0
+
+			// set cells alive state equal to the result of a random float, between 0.0 and 1.0,
+			// being less than the -density (0.15 by default). Each cell then has a chance of -density of starting out
+			// alive.
+			// A -seed-pattern other than uniform gives each cell a chance of its own instead.
+			// When the board spells out some text or has patterns placed on it instead, or Langton's Ant is to walk it,
+			// every cell starts out dead, as it does with -density 0, without rolling for any of them.
+			if cfg.Text == "" && len(cfg.Place) == 0 && cfg.Automaton != "ant" && cfg.Density > 0 {
+				c.alive = rng.Float64() < chance(x, c.y)
+				c.aliveNext = c.alive
+				if cfg.Automaton == "quadlife" {
+					c.color = uint8(rng.Intn(quadLifeColors))
+				}
+			}
+		}
+	}
+
+	if cfg.Text != "" {
+		stampText(cells, cfg.Text)
+	} else if cfg.Symmetry != "none" && len(cfg.Place) == 0 {
+		mirror(cells, cfg.Symmetry)
+	}
+	stampPlacements(cells)
+
+	if cfg.FrozenBorder == "dead" || cfg.FrozenBorder == "alive" {
+		freezeBorder(cells, cfg.FrozenBorder == "alive")
+	}
+}
+
+// freezeBorder brings every cell in the outermost ring of the board to life or kills it, before Step keeps it that
+// way for good.
+func freezeBorder(cells [][]*cell, alive bool) {
+	for x := range cells {
+		for y, c := range cells[x] {
+			if x == 0 || y == 0 || x == len(cells)-1 || y == len(cells[x])-1 {
+				c.alive, c.aliveNext = alive, alive
+				c.dying, c.dyingNext = 0, 0
+				c.color = 0
+			}
+		}
+	}
+}
+
+// mirror copies the randomly seeded cells of one side of the board onto the other, reflecting them across the middle.
+// horizontal symmetry reflects the left half onto the right, vertical reflects the bottom half onto the top and quad
+// does both, reflecting the bottom left quarter onto the other three.
+func mirror(cells [][]*cell, symmetry string) {
+	for x := range cells {
+		for y, c := range cells[x] {
+			// Cells closer to the start of a mirrored axis than its end are kept, the rest are copied from their
+			// reflection on the other side, which is always one of the kept cells.
+			fromX, fromY := x, y
+			if symmetry == "horizontal" || symmetry == "quad" {
+				if reflected := len(cells) - 1 - x; reflected < x {
+					fromX = reflected
+				}
+			}
+			if symmetry == "vertical" || symmetry == "quad" {
+				if reflected := len(cells[x]) - 1 - y; reflected < y {
+					fromY = reflected
+				}
+			}
+
+			c.alive = cells[fromX][fromY].alive
+			c.aliveNext = c.alive
+			c.color = cells[fromX][fromY].color
+		}
+	}
+}
+
+func newCell(x, y int) *cell {
+	return &cell{x: x, y: y}
+}
+
+// checkState determines the state of the cell for the next tick of the game from its number of live neighbors,
+// following the rule.
+// The cell's current state is left untouched until the board commits the tick, see Board.Step.
+//
+// Under Conway's rule, B3/S23:
+// 1. Any live cell with fewer than two live neighbours dies, as if caused by underpopulation.
+// 2. Any live cell with two or three live neighbours lives on to the next generation.
+// 3. Any live cell with more than three live neighbours dies, as if by overpopulation.
+// 4. Any dead cell with exactly three live neighbours becomes a live cell, as if by reproduction.
+func (c *cell) checkState(r Rule, liveNeighbors int) {
+	next := r.NextState(c.state(), liveNeighbors)
+	c.aliveNext = next == 1
+	c.dyingNext = 0
+	if next > 1 {
+		c.dyingNext = uint8(next - 1)
+	}
+
+	// A dead cell's color is never read by its neighbors, so the newborn can take its color right away.
+	if c.aliveNext && !c.alive && cfg.Automaton == "quadlife" {
+		c.color = c.newbornColor()
+	}
+}
+
+// state returns the state of the cell as numbered by Generations rules: 0 is dead, 1 is alive and 2 and up are the
+// dying states.
+func (c *cell) state() int {
+	if c.alive {
+		return 1
+	}
+	if c.dying > 0 {
+		return int(c.dying) + 1
+	}
+	return 0
+}
+
+// wrapIndex wraps i around to between 0 and n-1, whichever side of that it is on and however far.
+func wrapIndex(i, n int) int {
+	return (i%n + n) % n
+}
+
+// outside stands in for the neighbors beyond the edges of a board which doesn't wrap. It is never stepped, so it
+// stays dead forever.
+var outside = &cell{}
+
+// linkNeighbors stores pointers to the eight neighbors of the cell. The board never changes size, so the neighbors
+// of a cell are found once up front instead of recomputing the wrapped coordinates on every tick of the game.
+// They only need linking again when the boundary changes. wrapX and wrapY wrap the left and right and the top and
+// bottom edges around, and flip wraps the left and right edges onto each other upside down, making the board a Klein
+// bottle. Past an edge which doesn't wrap, the neighbors are dead, or alive if aliveEdges says so for that edge.
+//
+// The board has to be rectangular, with every column as tall as the first, as makeCells makes it. Its width and
+// height are taken from the board as a whole and never from the column a neighbor happens to be in, so this holds
+// for boards of any size, down to a single row or column, where a cell on a board which wraps is its own neighbor
+// across the edges it touches on both sides.
+func (c *cell) linkNeighbors(cells [][]*cell, wrapX, wrapY, flip bool, aliveEdges [4]bool) {
+	width, height := len(cells), len(cells[0])
+	var i int
+	add := func(x, y int) {
+		// Past an edge which doesn't wrap, there is nothing but dead cells, or live ones.
+		if beyond := beyondEdges(x, y, width, height, wrapX, wrapY, aliveEdges); beyond != nil {
+			c.neighbors[i] = beyond
+			i++
+			return
+		}
+
+		// If we're past an edge, check the other side of the board. Across a flipped edge, that is the other side
+		// upside down. Flipping first means a neighbor past a corner is then wrapped to the right row below.
+		if x < 0 || x >= width {
+			if flip {
+				y = height - 1 - y
+			}
+			x = wrapIndex(x, width)
+		}
+		y = wrapIndex(y, height)
+
+		c.neighbors[i] = cells[x][y]
+		i++
+	}
+
+	add(c.x-1, c.y)   // To the left
+	add(c.x+1, c.y)   // To the right
+	add(c.x, c.y+1)   // up
+	add(c.x, c.y-1)   // down
+	add(c.x-1, c.y+1) // top-left
+	add(c.x+1, c.y+1) // top-right
+	add(c.x-1, c.y-1) // bottom-left
+	add(c.x+1, c.y-1) // bottom-right
+}
+
+// liveNeighbors returns the number of live neighbors for a cell, as linked by linkNeighbors. A neighbor linked more
+// than once, as on a board only a cell or two wide, is counted every time.
+func (c *cell) liveNeighbors() int {
+	var liveCount int
+	for _, n := range c.neighbors {
+		if n.alive {
+			liveCount++
+		}
+	}
+
+	return liveCount
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// glider is a glider heading up and to the right, one cell along each axis every four generations.
+var glider = [][2]int{{0, 2}, {1, 2}, {2, 2}, {2, 1}, {1, 0}}
+
+// TestCylinder runs a glider on boards which wrap around only one axis, checking that it comes back on the opposite
+// side along the axis which wraps and stops against the edge of the one which doesn't.
+func TestCylinder(t *testing.T) {
+	for _, tc := range []struct {
+		axis          string
+		columns, rows int
+	}{
+		{"x", 10, 40},
+		{"y", 40, 10},
+	} {
+		b := SeededBoard(tc.rows, tc.columns, 0, 1)
+		b.wrapAxis = tc.axis
+		b.linkNeighbors()
+		for _, c := range glider {
+			b.set(2+c[0], 2+c[1], true)
+		}
+
+		// After 32 generations the glider has moved 8 cells along each axis, off the edge of the board along the
+		// axis which wraps and back in on the opposite side.
+		for i := 0; i < 32; i++ {
+			b.Step()
+		}
+		var want [][2]int
+		for _, c := range glider {
+			want = append(want, [2]int{wrapIndex(10+c[0], tc.columns), wrapIndex(10+c[1], tc.rows)})
+		}
+		want = offsets(0, 0, want...)
+		if got := liveAt(b); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("-wrap %s: glider at %v after wrapping, want %v", tc.axis, got, want)
+		}
+
+		// Along the axis which doesn't wrap, it runs into the edge and settles there as a block.
+		settled := false
+		for i := 0; i < 500 && !settled; i++ {
+			settled = !b.Step()
+		}
+		if !settled {
+			t.Fatalf("-wrap %s: glider never stopped", tc.axis)
+		}
+		if p := b.Population(); p != 4 {
+			t.Fatalf("-wrap %s: glider settled into %d cells, want a block", tc.axis, p)
+		}
+		for _, c := range liveAt(b) {
+			if tc.axis == "x" && c[1] < tc.rows-2 || tc.axis == "y" && c[0] < tc.columns-2 {
+				t.Fatalf("-wrap %s: cell %v isn't against the edge which doesn't wrap", tc.axis, c)
+			}
+		}
+	}
+}
+
+// TestLinkNeighborsThin links the neighbors of boards a single cell wide or tall, where a cell on a board which wraps
+// is a neighbor of itself, and checks how many live neighbors chosen cells have on a torus, a board with fixed edges
+// and a Klein bottle.
+func TestLinkNeighborsThin(t *testing.T) {
+	for _, tc := range []struct {
+		columns, rows       int
+		alive               [][2]int
+		cell                [2]int
+		torus, fixed, klein int
+	}{
+		{1, 5, [][2]int{{0, 0}, {0, 1}}, [2]int{0, 0}, 5, 1, 3},
+		{1, 5, [][2]int{{0, 0}, {0, 1}}, [2]int{0, 2}, 3, 1, 3},
+		{5, 1, [][2]int{{0, 0}, {1, 0}}, [2]int{0, 0}, 5, 1, 5},
+		{5, 1, [][2]int{{0, 0}, {1, 0}}, [2]int{2, 0}, 3, 1, 3},
+		{1, 1, [][2]int{{0, 0}}, [2]int{0, 0}, 8, 0, 8},
+	} {
+		b := SeededBoard(tc.rows, tc.columns, 0, 1)
+		for _, c := range tc.alive {
+			b.set(c[0], c[1], true)
+		}
+
+		for _, topology := range []struct {
+			name        string
+			wrap, klein bool
+			want        int
+		}{
+			{"torus", true, false, tc.torus},
+			{"fixed", false, false, tc.fixed},
+			{"klein", true, true, tc.klein},
+		} {
+			b.wrap, b.klein = topology.wrap, topology.klein
+			b.linkNeighbors()
+			if got := b.cells[tc.cell[0]][tc.cell[1]].liveNeighbors(); got != topology.want {
+				t.Errorf("%d by %d %s board with %v alive: cell %v has %d live neighbors, want %d",
+					tc.columns, tc.rows, topology.name, tc.alive, tc.cell, got, topology.want)
+			}
+		}
+	}
+}
+
+func TestWrapIndex(t *testing.T) {
+	for _, tc := range []struct{ i, n, want int }{
+		{0, 5, 0},
+		{4, 5, 4},
+		{-1, 5, 4},
+		{5, 5, 0},
+		{-5, 5, 0},
+		{-6, 5, 4},
+		{12, 5, 2},
+		{-1, 1, 0},
+		{1, 1, 0},
+	} {
+		if got := wrapIndex(tc.i, tc.n); got != tc.want {
+			t.Errorf("wrapIndex(%d, %d) = %d, want %d", tc.i, tc.n, got, tc.want)
+		}
+	}
+}
+
+// BenchmarkStep steps a 1000 by 1000 board seeded at the default density.
+func BenchmarkStep(b *testing.B) {
+	board := SeededBoard(1000, 1000, threshold, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		board.Step()
+	}
+}
+
+// wrappedNeighbors counts the live neighbors of the cell at (x, y) on a torus by wrapping their coordinates around
+// the edges on every call, the way cells were counted before linkNeighbors linked them up front. It is only kept to
+// compare BenchmarkLinkedNeighbors against.
+func wrappedNeighbors(cells [][]*cell, x, y int) int {
+	var liveCount int
+	add := func(x, y int) {
+		// If we're at an edge, check the other side of the board.
+		if x == len(cells) {
+			x = 0
+		} else if x == -1 {
+			x = len(cells) - 1
+		}
+		if y == len(cells[x]) {
+			y = 0
+		} else if y == -1 {
+			y = len(cells[x]) - 1
+		}
+
+		if cells[x][y].alive {
+			liveCount++
+		}
+	}
+
+	add(x-1, y)
+	add(x+1, y)
+	add(x, y+1)
+	add(x, y-1)
+	add(x-1, y+1)
+	add(x+1, y+1)
+	add(x-1, y-1)
+	add(x+1, y-1)
+
+	return liveCount
+}
+
+// BenchmarkLinkedNeighbors counts the live neighbors of every cell of a 1000 by 1000 board through the neighbors
+// linked by linkNeighbors.
+func BenchmarkLinkedNeighbors(b *testing.B) {
+	board := SeededBoard(1000, 1000, threshold, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for x := range board.cells {
+			for _, c := range board.cells[x] {
+				c.liveNeighbors()
+			}
+		}
+	}
+}
+
+// BenchmarkWrappedNeighbors counts the same neighbors as BenchmarkLinkedNeighbors, wrapping their coordinates every
+// time, see wrappedNeighbors.
+func BenchmarkWrappedNeighbors(b *testing.B) {
+	board := SeededBoard(1000, 1000, threshold, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for x := range board.cells {
+			for y := range board.cells[x] {
+				wrappedNeighbors(board.cells, x, y)
+			}
+		}
+	}
+}
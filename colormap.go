@@ -0,0 +1,67 @@
+package main
+
+// colormap is a gradient of colors a cell goes through as it ages under a Generations rule, chosen with -colormap. It
+// runs from the color of a live cell to that of a cell in its last dying state, in evenly spaced stops which are
+// blended into each other in between.
+type colormap [][3]float32
+
+// colormaps are the colormaps that can be chosen with -colormap, by name, besides theme. They run from bright to dark,
+// so that cells fade as they die like they do in the themes.
+var colormaps = map[string]colormap{
+	// viridis goes from yellow through green and blue to purple, and stays legible to the colorblind and in grayscale.
+	"viridis": {
+		{0.993, 0.906, 0.144},
+		{0.369, 0.789, 0.383},
+		{0.128, 0.567, 0.551},
+		{0.231, 0.322, 0.546},
+		{0.267, 0.005, 0.329},
+	},
+	// plasma goes from yellow through orange and pink to a deep blue.
+	"plasma": {
+		{0.940, 0.975, 0.131},
+		{0.973, 0.585, 0.252},
+		{0.798, 0.280, 0.470},
+		{0.494, 0.012, 0.658},
+		{0.050, 0.030, 0.528},
+	},
+	"grayscale": {
+		{1, 1, 1},
+		{0.15, 0.15, 0.15},
+	},
+}
+
+// currentColormap returns the colormap chosen with -colormap. The theme colormap is the simplest of them, blending
+// the cell color of the -theme into its faded color.
+func currentColormap() colormap {
+	if m, ok := colormaps[cfg.Colormap]; ok {
+		return m
+	}
+
+	th := currentTheme()
+	return colormap{th.cell, th.faded}
+}
+
+// at returns the color a fraction t of the way along the colormap, from 0 at its first stop to 1 at its last.
+func (m colormap) at(t float32) [3]float32 {
+	if t <= 0 {
+		return m[0]
+	}
+	if t >= 1 {
+		return m[len(m)-1]
+	}
+
+	// Find the two stops t falls between, and how far it is from the first of them to the second.
+	pos := t * float32(len(m)-1)
+	i := int(pos)
+	frac := pos - float32(i)
+	var rgb [3]float32
+	for j := range rgb {
+		rgb[j] = m[i][j] + (m[i+1][j]-m[i][j])*frac
+	}
+	return rgb
+}
+
+// colormapNames returns the names of the colormaps, theme first and the rest in alphabetical order.
+func colormapNames() []string {
+	return append([]string{"theme"}, sortedNames(colormaps)...)
+}
@@ -0,0 +1,655 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// config holds the settings that can be changed from the command line. They can also be saved to and loaded from a
+// JSON file, where each setting is named after its flag.
+type config struct {
+	// Title is the title of the window, followed by the status of the game.
+	Title string `json:"title"`
+	// Kiosk shows the board on its own for leaving it running unattended, like a screensaver: the window covers the
+	// whole screen without a border or a cursor, nothing but the board is drawn, and the board starts over from a new
+	// seed whenever it settles into a still life or comes back to a state it has been in before.
+	Kiosk bool `json:"kiosk"`
+	// MSAA is the number of samples used for multisample anti-aliasing, 0 disables it.
+	MSAA int `json:"msaa"`
+	// GLMajor and GLMinor are the version of OpenGL the window asks for, which the shaders are compiled for. The game
+	// is bound to OpenGL 4.1 and needs at least that, and -gpu at least 4.3, which it asks for by itself.
+	GLMajor int `json:"gl-major"`
+	GLMinor int `json:"gl-minor"`
+	// Shape is how a live cell is drawn, either "square" or "circle".
+	Shape string `json:"shape"`
+	// Fill is the radius of a circular cell as a fraction of its square, 1 touches the edges.
+	Fill float64 `json:"fill"`
+	// Gap is the fraction of each cell's square left empty around it, separating neighboring cells.
+	Gap float64 `json:"gap"`
+	// VSync is the swap interval, 1 waits for the display's vertical sync before swapping buffers and 0 disables it.
+	VSync int `json:"vsync"`
+	// GPU runs the game in a compute shader when OpenGL 4.3 is available instead of on the CPU.
+	GPU bool `json:"gpu"`
+	// Graph is the number of generations the population graph in the corner of the window covers, 0 hides it.
+	Graph int `json:"graph"`
+	// Theme is the name of the colors the board is drawn in, see themes.
+	Theme string `json:"theme"`
+	// Coloring is how cells are colored, "age" tints dying and QuadLife cells by their state and "flat" draws every cell
+	// in the theme's cell color. The C key switches between them.
+	Coloring string `json:"coloring"`
+	// Colormap is the gradient cells are colored along by their age with -coloring age, see colormaps. "theme" blends
+	// the cell color of the Theme into its faded color.
+	Colormap string `json:"colormap"`
+	// MaxDraw is the most live cells drawn in a frame, the rest are simulated but not drawn, to tell how much of a
+	// frame is spent drawing. 0 draws them all.
+	MaxDraw int `json:"maxdraw"`
+	// InvertRender draws the dead cells rather than the live ones, which are left as gaps. Unlike the I key, it doesn't
+	// change the cells themselves.
+	InvertRender bool `json:"invert-render"`
+	// Grid draws lines between the cells, which are left out while the cells are too small on screen for them to help,
+	// see drawGrid.
+	Grid bool `json:"grid"`
+	// Parity tints the cells of odd generations a little differently from those of even ones, so that the phase of
+	// oscillators can be seen, and a period 2 oscillator stands out from a still life.
+	Parity bool `json:"parity"`
+	// CellShader is a file of GLSL defining the shade function of the fragment shader, which decides the colour of
+	// every fragment of a cell, see cellFragmentShader. Empty leaves the cells as they are.
+	CellShader string `json:"cell-shader"`
+	// Columns and Rows are the number of cells across and up the board. The window is as big as it can be within
+	// width by height pixels with the same proportions as the board.
+	Columns int `json:"columns"`
+	Rows    int `json:"rows"`
+	// FPS is the number of generations per second the game starts out running at, which the up and down arrows change.
+	FPS float64 `json:"fps"`
+	// Renderer is what the board is shown with, "gl" for an OpenGL window, "points" for an OpenGL window drawing each
+	// cell as a single point, which is quicker on very large boards, or "terminal" for text in the terminal.
+	Renderer string `json:"renderer"`
+
+	// Run simulates this many generations without opening a window and reports the resulting board, 0 opens a window.
+	Run int `json:"run"`
+	// Soups runs this many random soups from the Seed onwards without opening a window and reports the ones which took
+	// the longest to settle, 0 opens a window.
+	Soups int `json:"soups"`
+	// SoupDir is the directory soups which lasted at least SoupLifespan generations or settled with at least
+	// SoupPopulation live cells are saved to as RLE files, empty saves none. A SoupPopulation of 0 saves none for their
+	// population.
+	SoupDir        string `json:"soup-dir"`
+	SoupLifespan   int    `json:"soup-lifespan"`
+	SoupPopulation int    `json:"soup-population"`
+	// BenchRender draws this many frames with each renderer and reports how long a frame took on average, 0 opens a
+	// window as usual.
+	BenchRender int `json:"bench-render"`
+	// FPSUnlimited runs the game in the window as fast as it can go, without waiting between frames, and logs how many
+	// generations go by every second. It is for measuring, as the speed keys have no effect.
+	FPSUnlimited bool `json:"fps-unlimited"`
+	// Hashlife computes the -run generations with the Hashlife algorithm rather than one generation at a time.
+	Hashlife bool `json:"hashlife"`
+	// Seed seeds the random starting state, 0 uses the current time.
+	Seed int64 `json:"seed"`
+	// SeedBank is a file of seeds, one on each line, which the game starts from one of at random and goes through in
+	// order every time the board is reset, see seedBank. Empty seeds the game from Seed.
+	SeedBank string `json:"seed-bank"`
+	// Automaton is the cellular automaton to run, "life" for Conway's Game of Life, "quadlife" for its four color
+	// variant or "ant" for Langton's Ant.
+	Automaton string `json:"automaton"`
+	// Rule is the rule cells live and die by, conway, highlife, daynight, seeds or any rule in B/S notation, optionally
+	// with a number of states for rules of the Generations family, see parseRule. Rules with B0 are simulated as two
+	// rules without it, see b0Rule.
+	Rule string `json:"rule"`
+	// Density is the chance of each cell starting out alive, 0 starts with an empty board.
+	Density float64 `json:"density"`
+	// Noise is the chance of each cell flipping between dead and alive every generation against the rule, 0 disables
+	// it. The M key switches it off and on again.
+	Noise float64 `json:"noise"`
+	// SeedPattern is how the chance of starting out alive is spread over the board, "uniform" gives every cell the
+	// Density, see seedPatterns for the others.
+	SeedPattern string `json:"seed-pattern"`
+	// Birth and Survive are the numbers of live neighbors a cell is born and survives with, separated by commas, as
+	// an alternative to giving the Rule in B/S notation.
+	Birth   string `json:"birth"`
+	Survive string `json:"survive"`
+	// Text is written across the middle of the board as the starting state instead of seeding it randomly.
+	Text string `json:"text"`
+	// Place stamps patterns onto the board at the start, each given as a pattern and the cell its top left corner goes
+	// on or else put in the middle of the board, see parsePlacement. The rest of the board starts out dead.
+	Place placeList `json:"place"`
+	// Symmetry mirrors the random starting state across the middle of the board, one of "none", "horizontal",
+	// "vertical" or "quad".
+	Symmetry string `json:"symmetry"`
+	// RuleExplorer switches to a random Life-like rule and reseeds the board every RuleExplorer generations, 0 keeps
+	// the -rule. The N key switches rule right away.
+	RuleExplorer int `json:"rule-explorer"`
+	// Boards is the number of boards run side by side, from the same starting state but each following its own rule
+	// from Rule.
+	Boards int `json:"boards"`
+	// Boundary is what lies beyond the edges of the board, "torus" wraps around to the opposite edge and "fixed" is
+	// nothing but dead cells. Wrapping can be toggled at runtime with the W key. "klein" wraps like a torus, except that
+	// the left and right edges are joined upside down, making a Klein bottle. "infinite" has no edges at all, the
+	// board grows as its live cells spread out. It can be given with -topology as well, as in -topology klein.
+	Boundary string `json:"boundary"`
+	// Wrap is which edges a torus wraps around, "xy" for all of them, "x" for only the left and right edges and "y"
+	// for only the top and bottom ones, which makes it a cylinder.
+	Wrap string `json:"wrap"`
+	// Edges is what lies beyond each of the top, right, bottom and left edges of the board, in place of the Boundary
+	// and Wrap, see parseEdges. Empty leaves them to the Boundary and Wrap, which by default wrap around all four.
+	Edges string `json:"edges"`
+	// Spaceships tints the cells of gliders and other spaceships, see detectSpaceships.
+	Spaceships bool `json:"spaceships"`
+	// HideWrapped leaves out the cells which just came to life across an edge of the board when drawing it, so that
+	// spaceships don't seem to jump from one edge to the other, see detectWrapped.
+	HideWrapped bool `json:"hide-wrapped"`
+	// FrozenBorder is what the outermost ring of cells is frozen as, "dead" or "alive", walling in the rest of the board
+	// whatever the rule says. "none" leaves it to follow the rule like any other cells.
+	FrozenBorder string `json:"frozen-border"`
+	// Verify checks every generation that the order the cells are visited in doesn't change the result, see
+	// Board.verifyOrder.
+	Verify bool `json:"verify"`
+	// Quiet silences logging of anything but fatal errors, Verbose adds diagnostics such as the seed.
+	Quiet   bool `json:"quiet"`
+	Verbose bool `json:"verbose"`
+
+	// PNGDir is the directory a PNG image of the board is written to every SnapshotEvery generations, empty disables it.
+	PNGDir        string `json:"pngdir"`
+	SnapshotEvery int    `json:"snapshot-every"`
+	// Heatmap is the PNG file an image of how long each cell has been alive for is saved to when the game ends, empty
+	// disables it.
+	Heatmap string `json:"heatmap"`
+	// Lifetimes is the CSV file a histogram of how many generations in a row cells stayed alive for is saved to when
+	// the game ends, empty disables it. Give a MaxGen or Run for the game to end on its own.
+	Lifetimes string `json:"lifetimes"`
+	// GIF is the file an animation of every generation is saved to when the game ends, empty disables it. GIFLoop
+	// saves it as soon as the board starts repeating itself instead, with just one period of the cycle, see recordGIF.
+	GIF     string `json:"gif"`
+	GIFLoop bool   `json:"gif-loop"`
+	// Record is the file every generation of the board is recorded to, to be played back with Replay, empty disables
+	// it. Replay is a recording to play back in the window rather than running the game, see replay.
+	Record string `json:"record"`
+	Replay string `json:"replay"`
+	// SVG is the file the board is saved to as an SVG image when S is pressed, or after the last generation of -run.
+	SVG string `json:"svg"`
+	// Save is the .gol file the board is saved to when the game ends, and Load one to load the board from at the
+	// start, to carry on from where it was saved at the size it was saved at, see saveState. Empty disables them.
+	Save string `json:"save"`
+	Load string `json:"load"`
+	// StepsPerFrame is the number of generations which go by between two frames, to fast-forward the game.
+	StepsPerFrame int `json:"steps-per-frame"`
+	// Skip runs this many generations before the first one is drawn or snapshot.
+	Skip int `json:"skip"`
+	// StartPaused starts the game paused, until Space is pressed.
+	StartPaused bool `json:"start-paused"`
+	// PauseAt pauses the game once it reaches this generation, 0 never does.
+	PauseAt int `json:"pause-at"`
+	// PauseOver pauses the game once its population grows past this many live cells, to catch a soup exploding, 0
+	// never does.
+	PauseOver int `json:"pause-over"`
+	// MaxGen closes the window after this many generations, 0 runs until it is closed.
+	MaxGen int `json:"maxgen"`
+
+	// CSV is the file a row of statistics is written to for every generation, empty disables it.
+	CSV string `json:"csv"`
+
+	// CPUProfile and MemProfile are the files the CPU and heap profiles are written to, empty disables them.
+	CPUProfile string `json:"cpuprofile"`
+	MemProfile string `json:"memprofile"`
+
+	// SaveConfig is the file all of the other settings are written to as JSON, and LoadConfig a file they are read
+	// from before the rest of the command line is applied.
+	SaveConfig string `json:"-"`
+	LoadConfig string `json:"-"`
+	// ListThemes, ListRules and ListPatterns print the themes, rule presets and patterns that can be chosen by name
+	// and exit.
+	ListThemes   bool `json:"-"`
+	ListRules    bool `json:"-"`
+	ListPatterns bool `json:"-"`
+}
+
+var cfg config
+
+// parseFlags registers the command line flags onto cfg, parses and validates them.
+func parseFlags() {
+	flag.StringVar(&cfg.Title, "title", "Conway's Game of Life", "`title` of the window")
+	flag.BoolVar(&cfg.Kiosk, "kiosk", false, "fill the screen with the board alone, without a cursor, and start over whenever it settles")
+	flag.IntVar(&cfg.MSAA, "msaa", 4, "number of `samples` used to anti-alias the window (0 disables)")
+	flag.IntVar(&cfg.GLMajor, "gl-major", 4, "`major` version of OpenGL to ask for")
+	flag.IntVar(&cfg.GLMinor, "gl-minor", 1, "`minor` version of OpenGL to ask for")
+	flag.StringVar(&cfg.Shape, "shape", "square", "shape of a live cell, square or circle")
+	flag.Float64Var(&cfg.Fill, "fill", 1.0, "`fraction` of its square a circular cell fills")
+	flag.Float64Var(&cfg.Gap, "gap", 0, "`fraction` of each cell's square left as a gutter between cells")
+	flag.IntVar(&cfg.VSync, "vsync", 1, "wait for vertical sync before swapping buffers (1 on, 0 off)")
+	flag.BoolVar(&cfg.GPU, "gpu", false, "compute generations on the GPU, needs OpenGL 4.3")
+	flag.IntVar(&cfg.Graph, "graph", 100, "graph the population of the last `N` generations in a corner of the window (0 hides it)")
+	flag.StringVar(&cfg.Theme, "theme", "classic", "`name` of the colors to draw the board in, see -list-themes")
+	flag.StringVar(&cfg.Coloring, "coloring", "age", "how to color cells, age tints them by their state and flat draws them all alike")
+	flag.StringVar(&cfg.Colormap, "colormap", "theme", "`name` of the gradient -coloring age colors cells along: theme, grayscale, plasma or viridis")
+	flag.BoolVar(&cfg.ListThemes, "list-themes", false, "list the themes that can be chosen with -theme and exit")
+	flag.BoolVar(&cfg.ListRules, "list-rules", false, "list the rules that can be given to -rule by name and exit")
+	flag.BoolVar(&cfg.ListPatterns, "list-patterns", false, "list the patterns that can be given to -place by name and exit")
+	flag.BoolVar(&cfg.Grid, "grid", false, "draw lines between the cells while they are big enough on screen")
+	flag.BoolVar(&cfg.InvertRender, "invert-render", false, "draw the dead cells and leave gaps for the live ones")
+	flag.BoolVar(&cfg.Parity, "parity", false, "tint the cells of odd generations to show the phase of oscillators")
+	flag.StringVar(&cfg.CellShader, "cell-shader", "", "`file` of GLSL defining vec4 shade(vec4 colour, vec2 local), which colours the cells")
+	flag.IntVar(&cfg.MaxDraw, "maxdraw", 0, "draw at most `N` live cells a frame to measure the cost of drawing (0 draws them all)")
+	flag.IntVar(&cfg.Columns, "columns", 100, "number of `cells` across the board")
+	flag.IntVar(&cfg.Rows, "rows", 100, "number of `cells` up the board")
+	flag.Float64Var(&cfg.FPS, "fps", 2, "number of `generations` per second to start out at, changed with the up and down arrows")
+	flag.StringVar(&cfg.Renderer, "renderer", "gl", "what to show the board with, gl for a window, points for a window drawing each cell as a point or terminal for text")
+	flag.IntVar(&cfg.Run, "run", 0, "simulate `N` generations without a window, then print the population and hash of the board")
+	flag.IntVar(&cfg.Soups, "soups", 0, "run `N` random soups without a window from -seed onwards and print the seeds which lasted longest")
+	flag.StringVar(&cfg.SoupDir, "soup-dir", "", "save the -soups which last or grow past the thresholds to `dir` as RLE files")
+	flag.IntVar(&cfg.SoupLifespan, "soup-lifespan", 1000, "save soups which last at least `N` generations to -soup-dir")
+	flag.IntVar(&cfg.SoupPopulation, "soup-population", 0, "save soups which settle with at least `N` live cells to -soup-dir (0 disables)")
+	flag.BoolVar(&cfg.FPSUnlimited, "fps-unlimited", false, "run as fast as possible and log the generations per second, for measuring, best with -vsync 0")
+	flag.IntVar(&cfg.BenchRender, "bench-render", 0, "draw `N` frames with each renderer and print the average frame time, best with -vsync 0")
+	flag.BoolVar(&cfg.Hashlife, "hashlife", false, "compute the -run generations with Hashlife, needs -boundary infinite")
+	flag.Int64Var(&cfg.Seed, "seed", 0, "`seed` for the random starting state, 0 uses the current time")
+	flag.StringVar(&cfg.SeedBank, "seed-bank", "", "`file` of seeds, one per line, to start from one of at random and go through on every reset")
+	flag.StringVar(&cfg.Automaton, "automaton", "life", "cellular automaton to run, life, quadlife or ant")
+	flag.StringVar(&cfg.Rule, "rule", "B3/S23", "`rule` to follow: a name from -list-rules or B/S notation, add a number of states for Generations rules like B2/S/3")
+	flag.Float64Var(&cfg.Density, "density", threshold, "`fraction` of cells alive at the start, 0 for an empty board")
+	flag.Float64Var(&cfg.Noise, "noise", 0, "chance of each cell flipping against the rule every generation, switched off and on with M")
+	flag.StringVar(&cfg.SeedPattern, "seed-pattern", "uniform", "how live cells are spread at the start: uniform, gaussian, stripes or checker")
+	flag.StringVar(&cfg.Birth, "birth", "", "numbers of live neighbors a dead cell is born with, like 3 or 3,6, instead of -rule")
+	flag.StringVar(&cfg.Survive, "survive", "", "numbers of live neighbors a live cell survives with, like 2,3, instead of -rule")
+	flag.StringVar(&cfg.Text, "text", "", "start with `text` written across the board instead of random cells")
+	flag.Var(&cfg.Place, "place", "start with a `pattern@x,y` such as glider@10,10, a known pattern or plaintext file, centered without @x,y, can be repeated")
+	flag.StringVar(&cfg.Symmetry, "symmetry", "none", "mirror the random starting state: none, horizontal, vertical or quad")
+	flag.IntVar(&cfg.RuleExplorer, "rule-explorer", 0, "try a random Life-like rule every `N` generations, or when N is pressed (0 disables)")
+	flag.IntVar(&cfg.Boards, "boards", 1, "run `N` boards side by side from the same seed, give each its own -rule separated by commas")
+	flag.StringVar(&cfg.Boundary, "boundary", "torus", "what lies beyond the board's edges: torus wraps around, klein wraps the sides upside down, fixed is dead cells, infinite has no edges")
+	// -topology is another name for -boundary, which reads more naturally for a Klein bottle.
+	flag.StringVar(&cfg.Boundary, "topology", "torus", "same as -boundary, like -topology klein")
+	flag.BoolVar(&cfg.Spaceships, "spaceships", false, "find gliders and other spaceships and draw them in their own color")
+	flag.BoolVar(&cfg.HideWrapped, "hide-wrapped", false, "don't draw cells which just came to life across an edge of the board, so nothing seems to jump across it")
+	flag.StringVar(&cfg.FrozenBorder, "frozen-border", "none", "freeze the outermost ring of cells as a wall: none, dead or alive")
+	flag.StringVar(&cfg.Wrap, "wrap", "xy", "which edges -boundary torus wraps around: xy for all, x for left and right or y for top and bottom")
+	flag.StringVar(&cfg.Edges, "edges", "", "what lies beyond the top, right, bottom and left `edges`, each wrap, dead or alive, like wrap,dead,wrap,dead")
+	flag.BoolVar(&cfg.Verify, "verify", false, "check every generation that the order cells are updated in doesn't matter")
+	flag.BoolVar(&cfg.Quiet, "quiet", false, "don't log anything but fatal errors")
+	flag.BoolVar(&cfg.Verbose, "verbose", false, "log diagnostics such as the seed and OpenGL version")
+	flag.StringVar(&cfg.PNGDir, "pngdir", "", "write a PNG image of the board to `dir` every -snapshot-every generations")
+	flag.IntVar(&cfg.SnapshotEvery, "snapshot-every", 1, "write a PNG image to -pngdir every `N` generations")
+	flag.StringVar(&cfg.Heatmap, "heatmap", "", "save a PNG `file` showing how long each cell was alive for when the game ends")
+	flag.StringVar(&cfg.Record, "record", "", "record every generation to `file`, to be played back with -replay")
+	flag.StringVar(&cfg.Replay, "replay", "", "play back a `file` recorded with -record instead of running the game, scrubbing through it with the left and right arrows")
+	flag.StringVar(&cfg.Lifetimes, "lifetimes", "", "save a CSV `file` counting how many generations in a row cells lived for when the game ends")
+	flag.StringVar(&cfg.GIF, "gif", "", "save an animated GIF `file` of every generation when the game ends")
+	flag.BoolVar(&cfg.GIFLoop, "gif-loop", false, "save the -gif as soon as the board repeats, with one period of it to loop seamlessly")
+	flag.StringVar(&cfg.SVG, "svg", "", "save the board as an SVG image to `file` when S is pressed, or at the end of -run")
+	flag.StringVar(&cfg.Save, "save", "", "save the board to a .gol `file` when the game ends, to -load it again")
+	flag.StringVar(&cfg.Load, "load", "", "start from the board saved to a .gol `file` with -save, size, rule and generation included")
+	flag.IntVar(&cfg.StepsPerFrame, "steps-per-frame", 1, "run `N` generations between frames to fast-forward")
+	flag.IntVar(&cfg.Skip, "skip", 0, "run `N` generations before drawing or taking snapshots")
+	flag.BoolVar(&cfg.StartPaused, "start-paused", false, "start paused, to edit the board before it runs")
+	flag.IntVar(&cfg.PauseAt, "pause-at", 0, "pause once the game reaches generation `N`, 0 never does")
+	flag.IntVar(&cfg.PauseOver, "pause-over", 0, "pause once the population grows past `N` live cells, 0 never does")
+	flag.IntVar(&cfg.MaxGen, "maxgen", 0, "close the window after `N` generations, 0 runs until it is closed")
+	flag.StringVar(&cfg.CSV, "csv", "", "write the population, births and deaths of every generation to a CSV `file`")
+	flag.StringVar(&cfg.CPUProfile, "cpuprofile", "", "write a CPU profile to `file`")
+	flag.StringVar(&cfg.MemProfile, "memprofile", "", "write a heap profile to `file` on exit")
+	flag.StringVar(&cfg.SaveConfig, "save-config", "", "save the settings to a JSON `file`")
+	flag.StringVar(&cfg.LoadConfig, "load-config", "", "load the settings from a JSON `file`, flags given on the command line take precedence")
+	flag.Parse()
+
+	if cfg.LoadConfig != "" {
+		if err := loadConfig(cfg.LoadConfig); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if cfg.ListThemes || cfg.ListRules || cfg.ListPatterns {
+		if cfg.ListThemes {
+			listThemes()
+		}
+		if cfg.ListRules {
+			listRules()
+		}
+		if cfg.ListPatterns {
+			listPatterns()
+		}
+		os.Exit(0)
+	}
+
+	if cfg.Shape != "square" && cfg.Shape != "circle" {
+		log.Fatalf("unknown -shape %q, expected square or circle", cfg.Shape)
+	}
+	if cfg.Fill <= 0 {
+		log.Fatalf("-fill must be positive, got %v", cfg.Fill)
+	}
+	if _, ok := themes[cfg.Theme]; !ok {
+		log.Fatalf("unknown -theme %q, expected one of %v", cfg.Theme, themeNames())
+	}
+	if cfg.Coloring != "age" && cfg.Coloring != "flat" {
+		log.Fatalf("unknown -coloring %q, expected age or flat", cfg.Coloring)
+	}
+	if _, ok := colormaps[cfg.Colormap]; !ok && cfg.Colormap != "theme" {
+		log.Fatalf("unknown -colormap %q, expected one of %v", cfg.Colormap, colormapNames())
+	}
+	if cfg.MaxDraw < 0 {
+		log.Fatalf("-maxdraw must not be negative, got %v", cfg.MaxDraw)
+	}
+	// The GPU draws every cell straight from its own buffers, and the terminal draws whole rows of them at a time.
+	if cfg.MaxDraw > 0 && (cfg.GPU || cfg.Renderer == "terminal") {
+		log.Fatal("-maxdraw doesn't work with -gpu or -renderer terminal")
+	}
+	// A board loaded with -load keeps the size it was saved at, which -columns and -rows only have to match when given.
+	if cfg.Load != "" {
+		columns, rows, err := stateSize(cfg.Load)
+		if err != nil {
+			log.Fatalf("-load: %v", err)
+		}
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "columns" && cfg.Columns != columns {
+				log.Fatalf("-load: %s is of a board of %d columns, not -columns %d", cfg.Load, columns, cfg.Columns)
+			}
+			if f.Name == "rows" && cfg.Rows != rows {
+				log.Fatalf("-load: %s is of a board of %d rows, not -rows %d", cfg.Load, rows, cfg.Rows)
+			}
+		})
+		cfg.Columns, cfg.Rows = columns, rows
+	}
+	if cfg.Columns < 1 || cfg.Rows < 1 {
+		log.Fatalf("-columns and -rows must be at least 1, got %v by %v", cfg.Columns, cfg.Rows)
+	}
+	if cfg.FPS < minFps || cfg.FPS > maxFps {
+		log.Fatalf("-fps must be between %v and %v, got %v", minFps, maxFps, cfg.FPS)
+	}
+	if cfg.VSync != 0 && cfg.VSync != 1 {
+		log.Fatalf("-vsync must be 0 or 1, got %v", cfg.VSync)
+	}
+	if cfg.Run < 0 {
+		log.Fatalf("-run must not be negative, got %v", cfg.Run)
+	}
+	if cfg.Density < 0 || cfg.Density > 1 {
+		log.Fatalf("-density must be between 0 and 1, got %v", cfg.Density)
+	}
+	// The GPU and boards without edges only draw their live cells.
+	if cfg.InvertRender && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-invert-render doesn't work with -gpu or -boundary infinite")
+	}
+	if cfg.Parity && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-parity doesn't work with -gpu or -boundary infinite")
+	}
+	// The other renderers and the GPU draw the cells with shaders of their own.
+	if cfg.CellShader != "" && (cfg.GPU || cfg.Boundary == "infinite" || cfg.Renderer != "gl") {
+		log.Fatal("-cell-shader only works with -renderer gl, without -gpu or -boundary infinite")
+	}
+	if cfg.CellShader != "" {
+		shade, err := readCellShader(cfg.CellShader)
+		if err != nil {
+			log.Fatalf("-cell-shader: %v", err)
+		}
+		cellShade = shade
+	}
+	if cfg.Noise < 0 || cfg.Noise > 1 {
+		log.Fatalf("-noise must be between 0 and 1, got %v", cfg.Noise)
+	}
+	// Only the cells on the CPU are mutated as they are stepped.
+	if cfg.Noise > 0 && (cfg.GPU || cfg.Boundary == "infinite" || cfg.Automaton == "ant") {
+		log.Fatal("-noise doesn't work with -gpu, -boundary infinite or -automaton ant")
+	}
+	if _, ok := seedPatterns[cfg.SeedPattern]; !ok {
+		log.Fatalf("unknown -seed-pattern %q, expected one of %v", cfg.SeedPattern, seedPatternNames())
+	}
+	if cfg.SeedBank != "" && cfg.Seed != 0 {
+		log.Fatal("-seed-bank doesn't work with -seed")
+	}
+	if cfg.Soups < 0 {
+		log.Fatalf("-soups must not be negative, got %v", cfg.Soups)
+	}
+	if cfg.Soups > 0 && cfg.Run > 0 {
+		log.Fatal("-soups doesn't work with -run")
+	}
+	if cfg.Soups > 0 && (cfg.Boundary == "infinite" || cfg.Boards > 1 || cfg.Noise > 0 || cfg.Automaton == "ant") {
+		log.Fatal("-soups doesn't work with -boundary infinite, -boards, -noise or -automaton ant")
+	}
+	if cfg.BenchRender < 0 {
+		log.Fatalf("-bench-render must not be negative, got %v", cfg.BenchRender)
+	}
+	if cfg.BenchRender > 0 && cfg.Run > 0 {
+		log.Fatal("-bench-render doesn't work with -run")
+	}
+	// Placements from a -load-config file haven't been through placeList.Set.
+	for _, s := range cfg.Place {
+		if _, err := parsePlacement(s); err != nil {
+			log.Fatal(err)
+		}
+	}
+	switch cfg.Symmetry {
+	case "none", "horizontal", "vertical", "quad":
+	default:
+		log.Fatalf("unknown -symmetry %q, expected none, horizontal, vertical or quad", cfg.Symmetry)
+	}
+	switch cfg.Automaton {
+	case "life", "quadlife", "ant":
+	default:
+		log.Fatalf("unknown -automaton %q, expected life, quadlife or ant", cfg.Automaton)
+	}
+	if cfg.RuleExplorer < 0 {
+		log.Fatalf("-rule-explorer must not be negative, got %v", cfg.RuleExplorer)
+	}
+	if cfg.RuleExplorer > 0 && (cfg.Run > 0 || cfg.GPU || cfg.Boards > 1) {
+		log.Fatal("-rule-explorer doesn't work with -run, -gpu or -boards")
+	}
+	// Recordings are made of and played back onto the cells on the CPU.
+	// Only the board on the CPU is saved and loaded, not the GPU's, the sparse board of a board without edges, the ant
+	// or any other boards.
+	if (cfg.Save != "" || cfg.Load != "") && (cfg.GPU || cfg.Boundary == "infinite" || cfg.Boards > 1 || cfg.Replay != "" || cfg.Automaton == "ant") {
+		log.Fatal("-save and -load don't work with -gpu, -boundary infinite, -boards, -replay or -automaton ant")
+	}
+	if cfg.Record != "" && (cfg.GPU || cfg.Boundary == "infinite" || cfg.Replay != "") {
+		log.Fatal("-record doesn't work with -gpu, -boundary infinite or -replay")
+	}
+	if cfg.Replay != "" && (cfg.Run > 0 || cfg.GPU || cfg.Boundary == "infinite" || cfg.Boards > 1 || cfg.RuleExplorer > 0 || cfg.Automaton == "ant") {
+		log.Fatal("-replay only plays back in a window, without -run, -gpu, -boundary infinite, -boards, -rule-explorer or -automaton ant")
+	}
+	if cfg.Boards < 1 || cfg.Boards > maxPanes {
+		log.Fatalf("-boards must be between 1 and %d, got %v", maxPanes, cfg.Boards)
+	}
+	if cfg.Boards > 1 && (cfg.Run > 0 || cfg.GPU || cfg.Boundary == "infinite" || cfg.Renderer != "gl") {
+		log.Fatal("-boards only works in an OpenGL window, without -run, -gpu or -boundary infinite")
+	}
+	if cfg.Birth != "" || cfg.Survive != "" {
+		var ruleGiven bool
+		flag.Visit(func(f *flag.Flag) {
+			ruleGiven = ruleGiven || f.Name == "rule"
+		})
+		if ruleGiven {
+			log.Fatal("give either -rule or -birth and -survive, not both")
+		}
+		// The lists are turned into B/S notation, so the rule is parsed like any other from then on.
+		r, err := countsRule(cfg.Birth, cfg.Survive)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// The lists would silently replace a rule given in a -load-config file too.
+		if cfg.Rule != flag.Lookup("rule").DefValue {
+			log.Fatalf("-load-config gives -rule %s, give either -rule or -birth and -survive, not both", cfg.Rule)
+		}
+		cfg.Rule = r.String()
+	}
+	rules := paneRules()
+	if len(rules) > cfg.Boards {
+		log.Fatalf("-rule has %d rules for %d boards", len(rules), cfg.Boards)
+	}
+	for _, name := range rules {
+		if _, err := newRule(name); err != nil {
+			log.Fatal(err)
+		}
+	}
+	switch cfg.Boundary {
+	case "torus", "klein", "fixed":
+	case "infinite":
+		// Only live cells are stored on a board without edges, which can't hold the endless dead background a B0
+		// rule brings to life, nor any dying cells or colors.
+		if r, _ := newRule(rules[0]); r.NextState(0, 0) == 1 || r.States() > 2 || cfg.Automaton != "life" {
+			log.Fatalf("-boundary infinite only supports Life-like rules without B0, got -automaton %s -rule %s", cfg.Automaton, cfg.Rule)
+		}
+	default:
+		log.Fatalf("unknown -boundary %q, expected torus, klein, fixed or infinite", cfg.Boundary)
+	}
+	// Spaceships are looked for among the cells on the CPU, like snapshots are taken of them.
+	if cfg.Spaceships && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-spaceships doesn't work with -gpu or -boundary infinite")
+	}
+	if cfg.HideWrapped && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-hide-wrapped doesn't work with -gpu or -boundary infinite")
+	}
+	switch cfg.FrozenBorder {
+	case "none":
+	case "dead", "alive":
+		// The border is only frozen by the rule on the CPU, which the ant doesn't follow.
+		if cfg.GPU || cfg.Boundary == "infinite" || cfg.Automaton == "ant" {
+			log.Fatal("-frozen-border doesn't work with -gpu, -boundary infinite or -automaton ant")
+		}
+	default:
+		log.Fatalf("unknown -frozen-border %q, expected none, dead or alive", cfg.FrozenBorder)
+	}
+	switch cfg.Wrap {
+	case "xy":
+	case "x", "y":
+		if cfg.Boundary != "torus" || cfg.GPU {
+			log.Fatal("-wrap x and -wrap y only work with -boundary torus, without -gpu")
+		}
+	default:
+		log.Fatalf("unknown -wrap %q, expected xy, x or y", cfg.Wrap)
+	}
+	if cfg.Edges != "" {
+		if _, err := parseEdges(cfg.Edges); err != nil {
+			log.Fatalf("-edges: %v", err)
+		}
+		if cfg.Boundary != "torus" || cfg.Wrap != "xy" || cfg.GPU {
+			log.Fatal("-edges sets every edge itself, it doesn't work with -boundary, -wrap or -gpu")
+		}
+	}
+	if cfg.Hashlife && (cfg.Run == 0 || cfg.Boundary != "infinite") {
+		log.Fatal("-hashlife only works with -run and -boundary infinite")
+	}
+	switch cfg.Renderer {
+	case "gl":
+	case "points", "terminal":
+		// Both of these draw their cells straight into the window of the OpenGL renderer, in their own way.
+		if cfg.GPU || cfg.Boundary == "infinite" {
+			log.Fatalf("-renderer %s doesn't work with -gpu or -boundary infinite", cfg.Renderer)
+		}
+	default:
+		log.Fatalf("unknown -renderer %q, expected gl, points or terminal", cfg.Renderer)
+	}
+	if cfg.Kiosk && cfg.Renderer == "terminal" {
+		log.Fatal("-kiosk needs a window, it doesn't work with -renderer terminal")
+	}
+	if cfg.Graph == 1 || cfg.Graph < 0 {
+		log.Fatalf("-graph must be 0 or at least 2 generations, got %v", cfg.Graph)
+	}
+	if cfg.SnapshotEvery < 1 {
+		log.Fatalf("-snapshot-every must be at least 1, got %v", cfg.SnapshotEvery)
+	}
+	if cfg.StepsPerFrame < 1 || cfg.StepsPerFrame > maxStepsPerFrame {
+		log.Fatalf("-steps-per-frame must be between 1 and %d, got %v", maxStepsPerFrame, cfg.StepsPerFrame)
+	}
+	if cfg.Skip < 0 {
+		log.Fatalf("-skip must not be negative, got %v", cfg.Skip)
+	}
+	if cfg.PauseAt < 0 {
+		log.Fatalf("-pause-at must not be negative, got %v", cfg.PauseAt)
+	}
+	if cfg.PauseOver < 0 {
+		log.Fatalf("-pause-over must not be negative, got %v", cfg.PauseOver)
+	}
+	// The population of the board isn't known when running on the GPU.
+	if cfg.PauseOver > 0 && cfg.GPU {
+		log.Fatal("-pause-over doesn't work with -gpu")
+	}
+	if cfg.MaxGen < 0 {
+		log.Fatalf("-maxgen must not be negative, got %v", cfg.MaxGen)
+	}
+	// Snapshots are taken of the cells on the CPU, which aren't stepped on the GPU or on a board without edges.
+	if cfg.PNGDir != "" && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-pngdir doesn't work with -gpu or -boundary infinite")
+	}
+	if cfg.SVG != "" && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-svg doesn't work with -gpu or -boundary infinite")
+	}
+	if cfg.Heatmap != "" && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-heatmap doesn't work with -gpu or -boundary infinite")
+	}
+	if cfg.Lifetimes != "" && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-lifetimes doesn't work with -gpu or -boundary infinite")
+	}
+	if cfg.GIF != "" && (cfg.GPU || cfg.Boundary == "infinite") {
+		log.Fatal("-gif doesn't work with -gpu or -boundary infinite")
+	}
+	if cfg.GIFLoop && cfg.GIF == "" {
+		log.Fatal("-gif-loop needs a -gif file to save to")
+	}
+	// The OpenGL bindings are for 4.1, and 4.6 is the latest version there is.
+	if cfg.GLMajor != 4 || cfg.GLMinor < 1 || cfg.GLMinor > 6 {
+		log.Fatalf("OpenGL %d.%d isn't supported, -gl-major and -gl-minor must give 4.1 to 4.6", cfg.GLMajor, cfg.GLMinor)
+	}
+	if cfg.Gap < 0 || cfg.Gap >= 1 {
+		log.Fatalf("-gap must be between 0 and 1, got %v", cfg.Gap)
+	}
+
+	if cfg.SaveConfig != "" {
+		if err := saveConfig(cfg.SaveConfig); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	switch {
+	case cfg.Quiet && cfg.Verbose:
+		log.Fatal("-quiet and -verbose can't be used together")
+	case cfg.Quiet:
+		logLevel = levelQuiet
+	case cfg.Verbose:
+		logLevel = levelVerbose
+	}
+}
+
+// loadConfig reads the settings from a JSON file into cfg. Settings missing from the file keep their defaults, and
+// flags given on the command line are applied again afterwards so that they override the file.
+func loadConfig(path string) error {
+	// The flags point into cfg, which is about to be overwritten, so their values have to be saved first.
+	given := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = f.Value.String()
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("reading %s: %v", path, err)
+	}
+	// A file saved with -birth and -survive holds the rule they were turned into as well, which is left to the lists,
+	// so that lists given on the command line replace it along with them.
+	if cfg.Birth != "" || cfg.Survive != "" {
+		if r, err := countsRule(cfg.Birth, cfg.Survive); err == nil && cfg.Rule == r.String() {
+			cfg.Rule = flag.Lookup("rule").DefValue
+		}
+	}
+
+	for name, value := range given {
+		// A list given on the command line replaces the one in the file rather than adding to it.
+		if l, ok := flag.Lookup(name).Value.(*placeList); ok {
+			*l = nil
+		}
+		if err := flag.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveConfig writes the settings to a JSON file.
+func saveConfig(path string) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
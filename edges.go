@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The edges of the board, in the order -edges gives them in.
+const (
+	edgeTop = iota
+	edgeRight
+	edgeBottom
+	edgeLeft
+)
+
+// aliveOutside stands in for the neighbors beyond an edge which -edges makes alive, like outside does for the dead
+// ones. It is never stepped, so it stays alive forever.
+var aliveOutside = &cell{alive: true}
+
+// parseEdges parses the -edges, what lies beyond the top, right, bottom and left edges of the board, separated by
+// commas. Each is "wrap" for the cells on the opposite edge, "dead" for dead cells or "alive" for live ones. Wrapping
+// joins two opposite edges to each other, so an edge only wraps if the one opposite it does too.
+func parseEdges(s string) ([4]string, error) {
+	var sides [4]string
+	fields := strings.Split(s, ",")
+	if len(fields) != len(sides) {
+		return sides, fmt.Errorf("%q should be four edges, top, right, bottom and left, like wrap,dead,wrap,dead", s)
+	}
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if field != "wrap" && field != "dead" && field != "alive" {
+			return sides, fmt.Errorf("unknown edge %q, expected wrap, dead or alive", field)
+		}
+		sides[i] = field
+	}
+
+	if (sides[edgeTop] == "wrap") != (sides[edgeBottom] == "wrap") {
+		return sides, fmt.Errorf("%q wraps only one of the top and bottom edges, they can only wrap around to each other", s)
+	}
+	if (sides[edgeLeft] == "wrap") != (sides[edgeRight] == "wrap") {
+		return sides, fmt.Errorf("%q wraps only one of the left and right edges, they can only wrap around to each other", s)
+	}
+	return sides, nil
+}
+
+// setEdges makes the board wrap around the edges which the sides given by parseEdges wrap, and have live cells
+// beyond the edges they make alive. Turning the wrapping off with the W key leaves those cells alive.
+func (b *Board) setEdges(sides [4]string) {
+	wrapX, wrapY := sides[edgeLeft] == "wrap", sides[edgeTop] == "wrap"
+	b.wrap = wrapX || wrapY
+	switch {
+	case wrapX && !wrapY:
+		b.wrapAxis = "x"
+	case wrapY && !wrapX:
+		b.wrapAxis = "y"
+	default:
+		b.wrapAxis = "xy"
+	}
+	for side, s := range sides {
+		b.aliveEdges[side] = s == "alive"
+	}
+}
+
+// beyondEdges returns the cell standing in for the neighbor at (x, y) of a board of width by height cells, when it
+// lies past an edge which doesn't wrap, or nil when it is on the board once the edges which wrap are wrapped around.
+// Past a corner it is alive only if both edges are, so it is dead beyond the corner between a dead and a live edge.
+func beyondEdges(x, y, width, height int, wrapX, wrapY bool, aliveEdges [4]bool) *cell {
+	past, alive := false, true
+	cross := func(side int) {
+		past = true
+		alive = alive && aliveEdges[side]
+	}
+	if !wrapX && x < 0 {
+		cross(edgeLeft)
+	}
+	if !wrapX && x >= width {
+		cross(edgeRight)
+	}
+	if !wrapY && y < 0 {
+		cross(edgeBottom)
+	}
+	if !wrapY && y >= height {
+		cross(edgeTop)
+	}
+
+	switch {
+	case !past:
+		return nil
+	case alive:
+		return aliveOutside
+	}
+	return outside
+}
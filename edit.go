@@ -0,0 +1,281 @@
+package main
+
+import (
+	"math"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// pattern is a small rectangle of cells, indexed like the board's cells as pattern[x][y] with y growing upwards,
+// where true is a live cell.
+type pattern [][]bool
+
+// The color the clipboard is previewed in beneath the cursor, and its alpha. It is see-through, so the cells it would
+// overwrite can still be seen beneath it.
+var ghostColor = [3]float32{0.5, 0.7, 1}
+
+const ghostAlpha = 0.5
+
+// rotate returns the pattern turned a quarter turn clockwise.
+func (p pattern) rotate() pattern {
+	width, height := len(p), len(p[0])
+	rotated := make(pattern, height)
+	for x := range rotated {
+		rotated[x] = make([]bool, width)
+		for y := range rotated[x] {
+			// The top row becomes the right column, read from top to bottom.
+			rotated[x][y] = p[width-1-y][x]
+		}
+	}
+
+	return rotated
+}
+
+// flipHorizontal returns the pattern mirrored left to right.
+func (p pattern) flipHorizontal() pattern {
+	flipped := make(pattern, len(p))
+	for x := range flipped {
+		flipped[x] = append([]bool(nil), p[len(p)-1-x]...)
+	}
+
+	return flipped
+}
+
+// flipVertical returns the pattern mirrored top to bottom.
+func (p pattern) flipVertical() pattern {
+	flipped := make(pattern, len(p))
+	for x := range flipped {
+		flipped[x] = make([]bool, len(p[x]))
+		for y := range flipped[x] {
+			flipped[x][y] = p[x][len(p[x])-1-y]
+		}
+	}
+
+	return flipped
+}
+
+// The largest brush, in cells along each side, see editor.paint.
+const maxBrush = 15
+
+// editor lets the user change the board with the mouse. Dragging with Shift held selects a rectangle of cells and
+// copies it to the clipboard, and clicking afterwards pastes the clipboard with its top left corner on the clicked
+// cell, overwriting the cells beneath it. Dragging with Ctrl held instead fills the rectangle with random cells, as
+// dense as the board was seeded. Before pasting, the clipboard can be turned with Q and flipped with H and V,
+// and a preview of it follows the cursor. Until something is copied, clicking paints with a square brush instead,
+// which grows and shrinks with ] and [.
+type editor struct {
+	board *Board
+
+	// brush is the number of cells along each side of the square painted by a click.
+	brush int
+
+	// The cell the selection started from, while one is being dragged, and whether it is to be filled rather than
+	// copied.
+	selecting      bool
+	filling        bool
+	startX, startY int
+
+	clipboard pattern
+}
+
+// mouseButton handles a press or release of a mouse button in the window.
+func (e *editor) mouseButton(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	if button != glfw.MouseButtonLeft {
+		return
+	}
+	x, y, ok := e.cellAt(w)
+
+	switch {
+	case action == glfw.Press && mods&(glfw.ModShift|glfw.ModControl) != 0:
+		e.selecting, e.startX, e.startY = ok, x, y
+		e.filling = mods&glfw.ModControl != 0
+	case action == glfw.Release && e.selecting:
+		e.selecting = false
+		if ok && e.filling {
+			e.fill(e.startX, e.startY, x, y)
+		} else if ok {
+			e.copy(e.startX, e.startY, x, y)
+		}
+	case action == glfw.Press && ok && e.clipboard != nil:
+		e.paste(x, y)
+	case action == glfw.Press && ok:
+		e.paint(x, y)
+	}
+}
+
+// key handles a key press in the window, changing the size of the brush or transforming the clipboard.
+func (e *editor) key(key glfw.Key) {
+	switch key {
+	case glfw.KeyLeftBracket:
+		if e.brush > 1 {
+			e.brush--
+		}
+		return
+	case glfw.KeyRightBracket:
+		if e.brush < maxBrush {
+			e.brush++
+		}
+		return
+	}
+	if e.clipboard == nil {
+		return
+	}
+
+	switch key {
+	case glfw.KeyQ:
+		e.clipboard = e.clipboard.rotate()
+	case glfw.KeyH:
+		e.clipboard = e.clipboard.flipHorizontal()
+	case glfw.KeyV:
+		e.clipboard = e.clipboard.flipVertical()
+	}
+}
+
+// drawGhost draws the live cells of the clipboard where it would be pasted, over the board drawn by program.
+func (e *editor) drawGhost(w *glfw.Window, program uint32) {
+	if e.clipboard == nil || e.selecting {
+		return
+	}
+	x, y, ok := e.cellAt(w)
+	if !ok {
+		return
+	}
+
+	colour := gl.GetUniformLocation(program, gl.Str("colour\x00"))
+	gl.Uniform4f(colour, ghostColor[0], ghostColor[1], ghostColor[2], ghostAlpha)
+
+	top := y - (len(e.clipboard[0]) - 1)
+	for px := range e.clipboard {
+		for py, alive := range e.clipboard[px] {
+			if c := e.board.at(x+px, top+py); alive && c != nil {
+				c.drawShape()
+			}
+		}
+	}
+
+	// Cells are drawn in the theme's cell color unless told otherwise, see draw.
+	cell := currentTheme().cell
+	gl.Uniform4f(colour, cell[0], cell[1], cell[2], 1)
+}
+
+// cellAt returns the coordinates of the cell under the cursor, or false when the cursor is outside the board.
+func (e *editor) cellAt(w *glfw.Window) (int, int, bool) {
+	cursorX, cursorY := w.GetCursorPos()
+	windowWidth, windowHeight := w.GetSize()
+	if windowWidth == 0 || windowHeight == 0 {
+		return 0, 0, false
+	}
+
+	// Turn the cursor position, in pixels from the top left corner, into OpenGL coordinates between -1 and 1 and undo
+	// the scaling which keeps the cells square, see setAspect.
+	scaleX, scaleY := aspectScale(windowWidth, windowHeight, cfg.Columns, cfg.Rows)
+	boardX := (cursorX/float64(windowWidth)*2 - 1) / float64(scaleX)
+	boardY := (1 - cursorY/float64(windowHeight)*2) / float64(scaleY)
+
+	x := int(math.Floor((boardX + 1) / 2 * float64(len(e.board.cells))))
+	y := int(math.Floor((boardY + 1) / 2 * float64(len(e.board.cells[0]))))
+	if x < 0 || x >= len(e.board.cells) || y < 0 || y >= len(e.board.cells[0]) {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// copy copies the rectangle of cells between two opposite corners, both included, to the clipboard.
+func (e *editor) copy(x1, y1, x2, y2 int) {
+	x1, y1, x2, y2 = rectangle(x1, y1, x2, y2)
+
+	e.clipboard = make(pattern, x2-x1+1)
+	for x := range e.clipboard {
+		e.clipboard[x] = make([]bool, y2-y1+1)
+		for y := range e.clipboard[x] {
+			e.clipboard[x][y] = e.board.cells[x1+x][y1+y].alive
+		}
+	}
+	logInfof("Copied %dx%d cells", x2-x1+1, y2-y1+1)
+}
+
+// fill replaces the rectangle of cells between two opposite corners, both included, with random cells, rolling the
+// board's random number generator for each of them the same way the board was seeded.
+func (e *editor) fill(x1, y1, x2, y2 int) {
+	x1, y1, x2, y2 = rectangle(x1, y1, x2, y2)
+	for x := x1; x <= x2; x++ {
+		for y := y1; y <= y2; y++ {
+			e.board.set(x, y, e.board.rng.Float64() < cfg.Density)
+			if cfg.Automaton == "quadlife" {
+				e.board.cells[x][y].color = uint8(e.board.rng.Intn(quadLifeColors))
+			}
+		}
+	}
+}
+
+// paint toggles the square of brush by brush cells around (x, y): when the clicked cell is dead the square is brought
+// to life, otherwise it is killed. Like paste, it wraps around the edges of a board which wraps.
+func (e *editor) paint(x, y int) {
+	alive := !e.board.at(x, y).alive
+	for px := x - (e.brush-1)/2; px <= x+e.brush/2; px++ {
+		for py := y - (e.brush-1)/2; py <= y+e.brush/2; py++ {
+			e.board.set(px, py, alive)
+		}
+	}
+}
+
+// rectangle returns the bottom left and top right corners of the rectangle between two opposite corners.
+func rectangle(x1, y1, x2, y2 int) (int, int, int, int) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	return x1, y1, x2, y2
+}
+
+// paste overwrites the cells beneath the clipboard with its top left corner at (x, y). On a board which wraps the
+// clipboard wraps around the edges too, otherwise whatever falls outside the board is cut off.
+func (e *editor) paste(x, y int) {
+	top := y - (len(e.clipboard[0]) - 1)
+	for px := range e.clipboard {
+		for py, alive := range e.clipboard[px] {
+			e.board.set(x+px, top+py, alive)
+		}
+	}
+}
+
+// at returns the cell at (x, y), wrapping the coordinates around the edges of a board which wraps. It returns nil
+// for coordinates beyond edges which don't.
+func (b *Board) at(x, y int) *cell {
+	columns, rows := len(b.cells), len(b.cells[0])
+	wrapX, wrapY := b.wraps()
+	if wrapX {
+		// On a Klein bottle every trip across the left or right edge turns the board upside down.
+		crossings := x / columns
+		if x < 0 {
+			crossings = (x+1)/columns - 1
+		}
+		if b.klein && crossings%2 != 0 {
+			y = rows - 1 - y
+		}
+		x = (x%columns + columns) % columns
+	}
+	if wrapY {
+		y = (y%rows + rows) % rows
+	}
+	if x < 0 || x >= columns || y < 0 || y >= rows {
+		return nil
+	}
+
+	return b.cells[x][y]
+}
+
+// set brings the cell at (x, y) to life or kills it, see at.
+func (b *Board) set(x, y int, alive bool) {
+	c := b.at(x, y)
+	if c == nil {
+		return
+	}
+
+	c.alive, c.aliveNext = alive, alive
+	c.dying, c.dyingNext = 0, 0
+	b.liveStale = true
+}
@@ -0,0 +1,39 @@
+package main
+
+import "math/rand"
+
+// explorer picks random Life-like rules for -rule-explorer, turning the game into a way of discovering automata.
+type explorer struct {
+	// The rules are drawn from their own random number generator, as the board's is reseeded with every new rule and
+	// would otherwise keep picking the same one when -seed is given.
+	rng *rand.Rand
+}
+
+// newExplorer returns an explorer drawing its rules from seed.
+func newExplorer(seed int64) *explorer {
+	return &explorer{rng: rand.New(rand.NewSource(seed))}
+}
+
+// next switches the board to a new random rule and reseeds it, logging the rule so that interesting ones can be
+// noted down.
+func (e *explorer) next(b *Board) rule {
+	r := e.randomRule()
+	b.rule = r
+	b.reset(newSeed())
+	logInfo("Exploring rule", r)
+
+	return r
+}
+
+// randomRule returns a rule where every neighbor count has an even chance of bringing a dead cell to life and of
+// keeping a live one alive. Dead cells are never born without live neighbors, as a B0 rule turns the whole
+// background on and off every generation.
+func (e *explorer) randomRule() rule {
+	r := rule{states: 2}
+	for count := 0; count <= 8; count++ {
+		r.birth[count] = count > 0 && e.rng.Intn(2) == 0
+		r.survive[count] = e.rng.Intn(2) == 0
+	}
+
+	return r
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"encoding/binary"
+	"image/color"
+	"image/color/palette"
+	"math"
+	"os"
+)
+
+// gifRecorder collects a frame of the board after every generation, to be saved as an animated -gif. Only the
+// compressed image data GIF stores for each frame is kept, which takes up a small fraction of the image itself with as
+// few colors as a board has, and the frames are written out one after the other when the GIF is saved. That way a long
+// game doesn't hold hundreds of full size images in memory, as it would to encode them with image/gif.
+type gifRecorder struct {
+	frames [][]byte
+	// The width and height of the frames in pixels, those of a snapshot of the board.
+	width, height int
+	// The index in the palette of every color found on the board so far. There are only a handful of them, so the
+	// nearest color in the palette is only looked up once for each rather than for every pixel.
+	nearest map[color.RGBA]uint8
+	// With -gif-loop, the frame each state of the board was first seen in, by the hash of the board.
+	seen map[uint64]int
+	// Whether the GIF has been saved, after which no more frames are added to it.
+	saved bool
+}
+
+// recordGIF adds a frame of the board to a GIF after every generation from the end of -skip onwards, if there is a -gif
+// file to save it to when the game ends. It returns nil otherwise, which is safe to save.
+//
+// With -gif-loop, the GIF is saved as soon as the board comes back to a state it was in before, the way runSoup tells
+// that a soup has settled. From then on it can only go round the same cycle again, so the frames from that state on
+// make up exactly one period of it, and the GIF loops without a seam. A board which never settles is saved when the
+// game ends, as without -gif-loop, so give a -maxgen or -run.
+func recordGIF(board *Board) *gifRecorder {
+	if cfg.GIF == "" {
+		return nil
+	}
+
+	g := &gifRecorder{nearest: make(map[color.RGBA]uint8)}
+	if cfg.GIFLoop {
+		g.seen = make(map[uint64]int)
+	}
+	if cfg.Skip == 0 {
+		g.add(board)
+	}
+	board.OnGeneration(func(b *Board, generation, population int) {
+		if g.saved || generation < cfg.Skip {
+			return
+		}
+		if g.seen != nil {
+			if first, ok := g.seen[b.Hash()]; ok {
+				g.frames = g.frames[first:]
+				logInfof("Found a cycle of period %d at generation %d", len(g.frames), generation)
+				g.save()
+				return
+			}
+		}
+		g.add(b)
+	})
+
+	return g
+}
+
+// add adds a frame of the board to the GIF, in the same colors as a snapshot, as near as the palette of a GIF gets.
+func (g *gifRecorder) add(b *Board) {
+	if g.seen != nil {
+		g.seen[b.Hash()] = len(g.frames)
+	}
+
+	img := snapshotImage(b)
+	g.width, g.height = img.Bounds().Dx(), img.Bounds().Dy()
+	pix := make([]byte, g.width*g.height)
+	for i := range pix {
+		c := color.RGBA{img.Pix[4*i], img.Pix[4*i+1], img.Pix[4*i+2], img.Pix[4*i+3]}
+		index, ok := g.nearest[c]
+		if !ok {
+			index = uint8(color.Palette(palette.Plan9).Index(c))
+			g.nearest[c] = index
+		}
+		pix[i] = index
+	}
+
+	// The pixels are compressed the way GIF stores them, with LZW codes starting out 8 bits wide for the 256 colors
+	// of the palette.
+	var data bytes.Buffer
+	w := lzw.NewWriter(&data, lzw.LSB, 8)
+	w.Write(pix)
+	w.Close()
+	g.frames = append(g.frames, data.Bytes())
+}
+
+// save writes the frames to the -gif file as an animation which loops forever. It is only saved once, so saving it
+// again when the game ends after -gif-loop saved it does nothing.
+func (g *gifRecorder) save() {
+	if g == nil || g.saved {
+		return
+	}
+	g.saved = true
+	if len(g.frames) == 0 {
+		logInfo("No generations to save to", cfg.GIF)
+		return
+	}
+
+	f, err := os.Create(cfg.GIF)
+	if err != nil {
+		panic(err)
+	}
+	w := bufio.NewWriter(f)
+	// The frames are as far apart as the generations of a game running at -fps, in hundredths of a second.
+	delay := uint16(math.Round(100 / cfg.FPS))
+
+	// The header gives the size of the animation and the palette every frame uses, followed by the NETSCAPE2.0
+	// extension, which makes it loop forever.
+	w.WriteString("GIF89a")
+	binary.Write(w, binary.LittleEndian, [2]uint16{uint16(g.width), uint16(g.height)})
+	w.Write([]byte{0xf7, 0, 0})
+	for _, c := range palette.Plan9 {
+		r, gr, b, _ := c.RGBA()
+		w.Write([]byte{uint8(r >> 8), uint8(gr >> 8), uint8(b >> 8)})
+	}
+	w.Write([]byte{0x21, 0xff, 11})
+	w.WriteString("NETSCAPE2.0")
+	w.Write([]byte{3, 1, 0, 0, 0})
+
+	for _, data := range g.frames {
+		// Every frame has a graphic control extension giving the delay after it, then covers the whole animation
+		// with the data of its pixels, in blocks of up to 255 bytes ended by an empty one.
+		w.Write([]byte{0x21, 0xf9, 4, 0})
+		binary.Write(w, binary.LittleEndian, delay)
+		w.Write([]byte{0, 0, 0x2c})
+		binary.Write(w, binary.LittleEndian, [4]uint16{0, 0, uint16(g.width), uint16(g.height)})
+		w.Write([]byte{0, 8})
+		for len(data) > 0 {
+			n := len(data)
+			if n > 255 {
+				n = 255
+			}
+			w.WriteByte(byte(n))
+			w.Write(data[:n])
+			data = data[n:]
+		}
+		w.WriteByte(0)
+	}
+	w.WriteByte(0x3b)
+
+	if err := w.Flush(); err != nil {
+		panic(err)
+	}
+	if err := f.Close(); err != nil {
+		panic(err)
+	}
+	logInfo("Saved", len(g.frames), "generations to", cfg.GIF)
+	// The frames aren't needed any more.
+	g.frames = nil
+}
@@ -3,6 +3,6 @@ module life/cells
 go 1.20
 
 require (
-	github.com/go-gl/gl v0.0.0-20211210172815-726fda9656d6 // indirect
-	github.com/go-gl/glfw v0.0.0-20221017161538-93cebf72946b // indirect
+	github.com/go-gl/gl v0.0.0-20211210172815-726fda9656d6
+	github.com/go-gl/glfw v0.0.0-20221017161538-93cebf72946b
 )
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	gl43 "github.com/go-gl/gl/v4.3-core/gl"
+)
+
+const (
+	// computeShaderSource runs one tick of the game for every cell at once on the GPU. Each invocation handles a
+	// single cell, counting its live neighbors in the current board (wrapping around the edges unless told otherwise)
+	// and writing the cell's next state into the next board. Cells are stored as 1 (alive) or 0 (dead). The rule is
+	// given as two bit masks, where bit n of birth is set when a dead cell with n live neighbors is born and bit n of
+	// survive when a live cell with n live neighbors survives.
+	computeShaderSource = `
+    layout(local_size_x = 16, local_size_y = 16) in;
+    layout(r8ui, binding = 0) uniform readonly uimage2D current;
+    layout(r8ui, binding = 1) uniform writeonly uimage2D next;
+    uniform bool wrap;
+    uniform uint birth;
+    uniform uint survive;
+    void main() {
+        ivec2 size = imageSize(current);
+        ivec2 pos = ivec2(gl_GlobalInvocationID.xy);
+        if (pos.x >= size.x || pos.y >= size.y) {
+            return;
+        }
+
+        uint liveCount = 0u;
+        for (int dx = -1; dx <= 1; dx++) {
+            for (int dy = -1; dy <= 1; dy++) {
+                ivec2 neighbor = pos + ivec2(dx, dy);
+                if (dx == 0 && dy == 0) {
+                    continue;
+                }
+                if (!wrap && (any(lessThan(neighbor, ivec2(0))) || any(greaterThanEqual(neighbor, size)))) {
+                    continue;
+                }
+                liveCount += imageLoad(current, (neighbor + size) % size).r;
+            }
+        }
+
+        bool alive = imageLoad(current, pos).r == 1u;
+        bool aliveNext = ((alive ? survive : birth) >> liveCount & 1u) == 1u;
+        imageStore(next, pos, uvec4(aliveNext ? 1u : 0u));
+    }
+` + "\x00"
+	// boardVertexShaderSource covers the whole window with a single square, again using gl_VertexID to find the
+	// corner, and passes the position within the board between 0 and 1 on to the fragment shader.
+	boardVertexShaderSource = `
+    uniform vec2 scale;
+    out vec2 uv;
+    const vec2 corners[6] = vec2[6](
+        vec2(-1, 1), vec2(-1, -1), vec2(1, -1),
+        vec2(-1, 1), vec2(1, 1), vec2(1, -1)
+    );
+    void main() {
+        uv = (corners[gl_VertexID] + 1.0) / 2.0;
+        gl_Position = vec4(corners[gl_VertexID] * scale, 0.0, 1.0);
+    }
+` + "\x00"
+	// boardFragmentShaderSource looks up the cell a fragment falls in and discards it when the cell is dead. The
+	// fragment's position within its cell is used to leave the gap and to round off circular cells, matching what
+	// newCell and fragmentShaderSource do for the cells drawn by the CPU.
+	boardFragmentShaderSource = `
+    in vec2 uv;
+    uniform usampler2D board;
+    uniform bool circle;
+    uniform float radius;
+    uniform float gap;
+    uniform vec4 colour;
+    out vec4 frag_colour;
+    void main() {
+        vec2 pos = uv * vec2(textureSize(board, 0));
+        if (texelFetch(board, ivec2(pos), 0).r == 0u) {
+            discard;
+        }
+
+        vec2 local = fract(pos) * 2.0 - 1.0;
+        if (any(greaterThan(abs(local), vec2(1.0 - gap)))) {
+            discard;
+        }
+        if (circle && length(local / (1.0 - gap)) > radius) {
+            discard;
+        }
+        frag_colour = colour;
+    }
+` + "\x00"
+
+	// The number of cells each compute shader work group handles along x and y, see local_size_x and local_size_y.
+	workGroupSize = 16
+)
+
+// gpuLife runs the game entirely on the GPU with a compute shader. The board lives in two textures, one holding the
+// current generation and one the next, which swap roles after every tick so the CPU never has to see the cells again.
+type gpuLife struct {
+	compute uint32
+	render  uint32
+	// The render program draws without any vertex data, but OpenGL still requires a vertex array to be bound.
+	vao uint32
+
+	boards [2]uint32
+	// The index in boards of the texture holding the current generation.
+	current int
+	// The number of generations computed so far.
+	generation int
+
+	width  int32
+	height int32
+}
+
+// newGPULife checks that the current context supports compute shaders and uploads the cells to the GPU.
+// It returns an error when the GPU can't be used, in which case the game should keep running on the CPU.
+func newGPULife(board *Board) (*gpuLife, error) {
+	if cfg.Automaton != "life" {
+		return nil, fmt.Errorf("the GPU can't run %s", cfg.Automaton)
+	}
+	if cfg.Boundary == "infinite" {
+		return nil, errors.New("the GPU can't run a board without edges")
+	}
+	if cfg.Boundary == "klein" {
+		return nil, errors.New("the GPU can't run a Klein bottle")
+	}
+	if board.rule.States() > 2 {
+		return nil, fmt.Errorf("the GPU can't run Generations rules like %v", board.rule)
+	}
+	if _, ok := board.rule.(b0Rule); ok {
+		return nil, fmt.Errorf("the GPU can't run B0 rules like %v", board.rule)
+	}
+	cells := board.cells
+	if err := gl43.Init(); err != nil {
+		return nil, fmt.Errorf("OpenGL 4.3 is not supported: %v", err)
+	}
+	var major, minor int32
+	gl43.GetIntegerv(gl43.MAJOR_VERSION, &major)
+	gl43.GetIntegerv(gl43.MINOR_VERSION, &minor)
+	if major < 4 || (major == 4 && minor < 3) {
+		return nil, fmt.Errorf("compute shaders need OpenGL 4.3, got %d.%d", major, minor)
+	}
+
+	g := &gpuLife{
+		width:  int32(len(cells)),
+		height: int32(len(cells[0])),
+	}
+
+	var err error
+	g.compute, err = linkProgram(map[uint32]string{
+		gl43.COMPUTE_SHADER: computeShaderSource,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.render, err = linkProgram(map[uint32]string{
+		gl43.VERTEX_SHADER:   boardVertexShaderSource,
+		gl43.FRAGMENT_SHADER: boardFragmentShaderSource,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gl43.UseProgram(g.render)
+	var circle int32
+	if cfg.Shape == "circle" {
+		circle = 1
+	}
+	gl43.Uniform1i(gl43.GetUniformLocation(g.render, gl43.Str("circle\x00")), circle)
+	gl43.Uniform1f(gl43.GetUniformLocation(g.render, gl43.Str("radius\x00")), float32(cfg.Fill))
+	gl43.Uniform1f(gl43.GetUniformLocation(g.render, gl43.Str("gap\x00")), float32(cfg.Gap))
+	cell := currentTheme().cell
+	gl43.Uniform4f(gl43.GetUniformLocation(g.render, gl43.Str("colour\x00")), cell[0], cell[1], cell[2], 1)
+	gl43.Uniform1i(gl43.GetUniformLocation(g.render, gl43.Str("board\x00")), 0)
+
+	gl43.GenVertexArrays(1, &g.vao)
+	g.setWrap(board.wrap)
+
+	var birth, survive uint32
+	for count := 0; count <= 8; count++ {
+		if board.rule.NextState(0, count) == 1 {
+			birth |= 1 << count
+		}
+		if board.rule.NextState(1, count) == 1 {
+			survive |= 1 << count
+		}
+	}
+	gl43.UseProgram(g.compute)
+	gl43.Uniform1ui(gl43.GetUniformLocation(g.compute, gl43.Str("birth\x00")), birth)
+	gl43.Uniform1ui(gl43.GetUniformLocation(g.compute, gl43.Str("survive\x00")), survive)
+
+	gl43.GenTextures(2, &g.boards[0])
+	for _, board := range g.boards {
+		gl43.BindTexture(gl43.TEXTURE_2D, board)
+		gl43.TexStorage2D(gl43.TEXTURE_2D, 1, gl43.R8UI, g.width, g.height)
+		// Integer textures can't be filtered, every fragment must read exactly one cell.
+		gl43.TexParameteri(gl43.TEXTURE_2D, gl43.TEXTURE_MIN_FILTER, gl43.NEAREST)
+		gl43.TexParameteri(gl43.TEXTURE_2D, gl43.TEXTURE_MAG_FILTER, gl43.NEAREST)
+	}
+	g.load(cells)
+
+	logVerbose("Running the game on the GPU")
+	return g, nil
+}
+
+// load uploads the cells to the GPU as the current generation.
+func (g *gpuLife) load(cells [][]*cell) {
+	// Texture rows run along y, so cell (x, y) is stored at y*width + x.
+	pixels := make([]uint8, g.width*g.height)
+	for x := range cells {
+		for _, c := range cells[x] {
+			if c.alive {
+				pixels[int32(c.y)*g.width+int32(c.x)] = 1
+			}
+		}
+	}
+
+	gl43.BindTexture(gl43.TEXTURE_2D, g.boards[g.current])
+	gl43.PixelStorei(gl43.UNPACK_ALIGNMENT, 1)
+	gl43.TexSubImage2D(gl43.TEXTURE_2D, 0, 0, 0, g.width, g.height, gl43.RED_INTEGER, gl43.UNSIGNED_BYTE, gl43.Ptr(pixels))
+}
+
+// step computes the next generation of the board.
+func (g *gpuLife) step() {
+	next := 1 - g.current
+
+	gl43.UseProgram(g.compute)
+	gl43.BindImageTexture(0, g.boards[g.current], 0, false, 0, gl43.READ_ONLY, gl43.R8UI)
+	gl43.BindImageTexture(1, g.boards[next], 0, false, 0, gl43.WRITE_ONLY, gl43.R8UI)
+	gl43.DispatchCompute(uint32(g.width+workGroupSize-1)/workGroupSize, uint32(g.height+workGroupSize-1)/workGroupSize, 1)
+
+	// Make sure every cell has been written before the next board is read, either by drawing or the next step.
+	gl43.MemoryBarrier(gl43.SHADER_IMAGE_ACCESS_BARRIER_BIT | gl43.TEXTURE_FETCH_BARRIER_BIT)
+	g.current = next
+	g.generation++
+}
+
+// setWrap switches the board between wrapping around its edges and having dead cells beyond them.
+func (g *gpuLife) setWrap(wrap bool) {
+	var value int32
+	if wrap {
+		value = 1
+	}
+	gl43.UseProgram(g.compute)
+	gl43.Uniform1i(gl43.GetUniformLocation(g.compute, gl43.Str("wrap\x00")), value)
+}
+
+// draw renders the current generation, sampling the board texture instead of drawing a vertex array per cell.
+func (g *gpuLife) draw() {
+	gl43.Clear(gl43.COLOR_BUFFER_BIT | gl43.DEPTH_BUFFER_BIT)
+	gl43.UseProgram(g.render)
+	gl43.ActiveTexture(gl43.TEXTURE0)
+	gl43.BindTexture(gl43.TEXTURE_2D, g.boards[g.current])
+	gl43.BindVertexArray(g.vao)
+	gl43.DrawArrays(gl43.TRIANGLES, 0, 6)
+}
+
+// linkProgram compiles the source of each shader type and links them into a program.
+func linkProgram(shaders map[uint32]string) (uint32, error) {
+	prog := gl43.CreateProgram()
+	for shaderType, source := range shaders {
+		shader, err := compileShader(source, shaderType)
+		if err != nil {
+			return 0, err
+		}
+		gl43.AttachShader(prog, shader)
+	}
+	gl43.LinkProgram(prog)
+
+	var status int32
+	gl43.GetProgramiv(prog, gl43.LINK_STATUS, &status)
+	if status == gl43.FALSE {
+		return 0, errors.New("failed to link program")
+	}
+
+	return prog, nil
+}
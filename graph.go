@@ -0,0 +1,114 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+const (
+	// The graph shaders draw straight in window coordinates, so the graph stays in its corner whatever the scale of
+	// the board.
+	graphVertexShaderSource = `
+    in vec2 vp;
+    void main() {
+        gl_Position = vec4(vp, 0.0, 1.0);
+    }
+` + "\x00"
+	graphFragmentShaderSource = `
+    out vec4 frag_colour;
+    void main() {
+        frag_colour = vec4(0.3, 1, 0.4, 1);
+    }
+` + "\x00"
+
+	// The area the graph is drawn in, in OpenGL coordinates: the top left corner of the window.
+	graphLeft   = -0.95
+	graphTop    = 0.95
+	graphWidth  = 0.4
+	graphHeight = 0.2
+)
+
+// populationGraph draws a line graph of the population over the last generations in a corner of the window.
+// The populations are kept in a ring buffer, so once it is full every new generation overwrites the oldest one.
+type populationGraph struct {
+	counts []int
+	// The index in counts the next population is written to, and how many of counts have been written.
+	next   int
+	length int
+
+	program uint32
+	vao     uint32
+	vbo     uint32
+	// The x and y coordinates of each point of the line, reused between frames.
+	points []float32
+}
+
+// newPopulationGraph returns a graph of the population over the last generations, for use once OpenGL is initialized.
+func newPopulationGraph(generations int) *populationGraph {
+	g := &populationGraph{
+		counts: make([]int, generations),
+		points: make([]float32, 0, 2*generations),
+	}
+
+	vertexShader, err := compileShader(graphVertexShaderSource, gl.VERTEX_SHADER)
+	if err != nil {
+		panic(err)
+	}
+	fragmentShader, err := compileShader(graphFragmentShaderSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		panic(err)
+	}
+	g.program = gl.CreateProgram()
+	gl.AttachShader(g.program, vertexShader)
+	gl.AttachShader(g.program, fragmentShader)
+	gl.LinkProgram(g.program)
+
+	// Unlike the cells, the line changes every frame, so its buffer is filled in by draw rather than here.
+	gl.GenBuffers(1, &g.vbo)
+	gl.GenVertexArrays(1, &g.vao)
+	gl.BindVertexArray(g.vao)
+	gl.EnableVertexAttribArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, g.vbo)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 0, nil)
+
+	return g
+}
+
+// record adds the population of the latest generation to the graph.
+func (g *populationGraph) record(population int) {
+	g.counts[g.next] = population
+	g.next = (g.next + 1) % len(g.counts)
+	if g.length < len(g.counts) {
+		g.length++
+	}
+}
+
+// draw renders the graph, scaled so that the highest population shown reaches the top of its area.
+func (g *populationGraph) draw() {
+	// A line needs at least two points.
+	if g.length < 2 {
+		return
+	}
+
+	// The oldest population is the one the next will overwrite, or the first when the buffer isn't full yet.
+	oldest := (g.next - g.length + len(g.counts)) % len(g.counts)
+	highest := 1
+	for i := 0; i < g.length; i++ {
+		if count := g.counts[(oldest+i)%len(g.counts)]; count > highest {
+			highest = count
+		}
+	}
+
+	g.points = g.points[:0]
+	for i := 0; i < g.length; i++ {
+		count := g.counts[(oldest+i)%len(g.counts)]
+		x := graphLeft + graphWidth*float32(i)/float32(len(g.counts)-1)
+		y := graphTop - graphHeight + graphHeight*float32(count)/float32(highest)
+		g.points = append(g.points, x, y)
+	}
+
+	gl.UseProgram(g.program)
+	gl.BindBuffer(gl.ARRAY_BUFFER, g.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(g.points), gl.Ptr(g.points), gl.DYNAMIC_DRAW)
+	gl.BindVertexArray(g.vao)
+	gl.DrawArrays(gl.LINE_STRIP, 0, int32(g.length))
+}
@@ -0,0 +1,82 @@
+package main
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+const (
+	// Cells drawn smaller than this many pixels across have no grid drawn around them, as the lines would crowd out the
+	// cells and shimmer as the board changes. Between this and twice as many pixels the grid fades in.
+	gridMinCellPixels = 4
+	// How opaque the grid is at its most, so that it stays in the background of the cells.
+	gridAlpha = 0.3
+)
+
+// The color of the grid lines, a gray which shows on both light and dark themes.
+var gridColor = [3]float32{0.5, 0.5, 0.5}
+
+// gridLines are the lines between the cells of a board of columns by rows cells, drawn over the board with -grid.
+type gridLines struct {
+	vao           uint32
+	columns, rows int
+}
+
+// drawGrid draws the lines between the cells over a board of columns by rows cells, when -grid is given and the cells
+// are big enough on screen to tell the lines apart. A board without edges shows more cells as it grows, so the number
+// of cells can change from one frame to the next. Nothing but the board is shown with -kiosk, so it has no grid.
+func (r *glRenderer) drawGrid(columns, rows int) {
+	if !cfg.Grid || cfg.Kiosk {
+		return
+	}
+	width, height := r.window.GetFramebufferSize()
+	if width == 0 || height == 0 {
+		return
+	}
+	scaleX, _ := aspectScale(width, height, columns, rows)
+	cellPixels := float32(width) * scaleX / float32(columns)
+	if cellPixels < gridMinCellPixels {
+		return
+	}
+	alpha := float32(gridAlpha)
+	if cellPixels < 2*gridMinCellPixels {
+		alpha *= (cellPixels - gridMinCellPixels) / gridMinCellPixels
+	}
+
+	if r.grid.vao == 0 || r.grid.columns != columns || r.grid.rows != rows {
+		r.grid = gridLines{vao: makeVao(gridPoints(columns, rows)), columns: columns, rows: rows}
+	}
+
+	gl.UseProgram(r.program)
+	colour := gl.GetUniformLocation(r.program, gl.Str("colour\x00"))
+	circle := gl.GetUniformLocation(r.program, gl.Str("circle\x00"))
+	gl.Uniform4f(colour, gridColor[0], gridColor[1], gridColor[2], alpha)
+	// Round cells are cut out of their squares by the fragment shader, which would cut the lines to pieces.
+	gl.Uniform1i(circle, 0)
+	// A board without edges moves its one square around to draw every cell, but the lines are already in place.
+	gl.Uniform2f(gl.GetUniformLocation(r.program, gl.Str("cellScale\x00")), 1, 1)
+	gl.Uniform2f(gl.GetUniformLocation(r.program, gl.Str("offset\x00")), 0, 0)
+
+	gl.BindVertexArray(r.grid.vao)
+	gl.DrawArrays(gl.LINES, 0, int32(2*(columns+1+rows+1)))
+
+	cell := currentTheme().cell
+	gl.Uniform4f(colour, cell[0], cell[1], cell[2], 1)
+	if cfg.Shape == "circle" {
+		gl.Uniform1i(circle, 1)
+	}
+}
+
+// gridPoints returns the ends of the lines between the cells of a board of columns by rows cells, in OpenGL coordinates
+// before correcting for the aspect ratio of the window like cellPoints, a line at a time: first the lines running up the
+// board and then the lines running across it.
+func gridPoints(columns, rows int) []float32 {
+	points := make([]float32, 0, 6*(columns+1+rows+1))
+	for x := 0; x <= columns; x++ {
+		edge := float32(x)/float32(columns)*2 - 1
+		points = append(points, edge, -1, 0, edge, 1, 0)
+	}
+	for y := 0; y <= rows; y++ {
+		edge := float32(y)/float32(rows)*2 - 1
+		points = append(points, -1, edge, 0, 1, edge, 0)
+	}
+
+	return points
+}
@@ -0,0 +1,297 @@
+package main
+
+// Hashlife computes generations of a board without edges by splitting it into a quadtree and remembering what every
+// distinct square of cells turns into. Patterns which repeat themselves in space or time are made of the same few
+// squares over and over, so after the first time each one is looked up rather than simulated, and a square 2^k cells
+// wide can be advanced by 2^(k-2) generations at once. This lets it reach far-future generations of such patterns in
+// time logarithmic in the number of generations, where stepping one generation at a time never would.
+
+// node is a square of 2^level by 2^level cells in the quadtree. Nodes are never modified once made and there is only
+// ever one node for each arrangement of cells, so nodes can be compared, and their results cached, by pointer.
+type node struct {
+	// The four quadrants of the square, each one level lower. North is towards lower y and west towards lower x.
+	// Leaves, at level 0, are a single cell and have no quadrants.
+	nw, ne, sw, se *node
+
+	level      int
+	population int
+}
+
+// successorKey identifies the result of advancing the center of a node by 2^step generations.
+type successorKey struct {
+	n    *node
+	step int
+}
+
+// hashlife holds the canonical nodes and the cached results of advancing them under a rule.
+type hashlife struct {
+	rule Rule
+
+	dead, alive *node
+	nodes       map[[4]*node]*node
+	empty       []*node
+	successors  map[successorKey]*node
+
+	// root holds the whole board, with its north west corner at (x, y).
+	root       *node
+	x, y       int
+	generation int
+}
+
+// newHashlife returns a hashlife universe following the rule, holding the given live cells.
+func newHashlife(r Rule, live map[[2]int]bool) *hashlife {
+	h := &hashlife{
+		rule:       r,
+		dead:       &node{},
+		alive:      &node{population: 1},
+		nodes:      make(map[[4]*node]*node),
+		successors: make(map[successorKey]*node),
+	}
+	h.empty = []*node{h.dead}
+
+	cells := make([][2]int, 0, len(live))
+	minX, minY, maxX, maxY := 0, 0, 1, 1
+	for pos := range live {
+		if len(cells) == 0 || pos[0] < minX {
+			minX = pos[0]
+		}
+		if len(cells) == 0 || pos[1] < minY {
+			minY = pos[1]
+		}
+		if len(cells) == 0 || pos[0]+1 > maxX {
+			maxX = pos[0] + 1
+		}
+		if len(cells) == 0 || pos[1]+1 > maxY {
+			maxY = pos[1] + 1
+		}
+		cells = append(cells, pos)
+	}
+
+	// The root has to be at least 4x4 cells, the smallest square that can be advanced.
+	level := 2
+	for 1<<level < maxX-minX || 1<<level < maxY-minY {
+		level++
+	}
+	h.x, h.y = minX, minY
+	h.root = h.build(cells, level, minX, minY)
+
+	return h
+}
+
+// join returns the canonical node made of the four quadrants.
+func (h *hashlife) join(nw, ne, sw, se *node) *node {
+	key := [4]*node{nw, ne, sw, se}
+	if n, ok := h.nodes[key]; ok {
+		return n
+	}
+
+	n := &node{
+		nw: nw, ne: ne, sw: sw, se: se,
+		level:      nw.level + 1,
+		population: nw.population + ne.population + sw.population + se.population,
+	}
+	h.nodes[key] = n
+	return n
+}
+
+// emptyNode returns the node at the level without any live cells.
+func (h *hashlife) emptyNode(level int) *node {
+	for len(h.empty) <= level {
+		e := h.empty[len(h.empty)-1]
+		h.empty = append(h.empty, h.join(e, e, e, e))
+	}
+
+	return h.empty[level]
+}
+
+// build returns the node at the level with its north west corner at (x, y) holding the given live cells,
+// which must all lie within it.
+func (h *hashlife) build(cells [][2]int, level, x, y int) *node {
+	if len(cells) == 0 {
+		return h.emptyNode(level)
+	}
+	if level == 0 {
+		return h.alive
+	}
+
+	half := 1 << (level - 1)
+	var quadrants [4][][2]int
+	for _, pos := range cells {
+		var i int
+		if pos[0] >= x+half {
+			i++
+		}
+		if pos[1] >= y+half {
+			i += 2
+		}
+		quadrants[i] = append(quadrants[i], pos)
+	}
+
+	return h.join(
+		h.build(quadrants[0], level-1, x, y),
+		h.build(quadrants[1], level-1, x+half, y),
+		h.build(quadrants[2], level-1, x, y+half),
+		h.build(quadrants[3], level-1, x+half, y+half),
+	)
+}
+
+// collect adds the live cells of n, with its north west corner at (x, y), to live.
+func (h *hashlife) collect(n *node, x, y int, live map[[2]int]bool) {
+	if n.population == 0 {
+		return
+	}
+	if n.level == 0 {
+		live[[2]int{x, y}] = true
+		return
+	}
+
+	half := 1 << (n.level - 1)
+	h.collect(n.nw, x, y, live)
+	h.collect(n.ne, x+half, y, live)
+	h.collect(n.sw, x, y+half, live)
+	h.collect(n.se, x+half, y+half, live)
+}
+
+// cells returns the live cells of the board.
+func (h *hashlife) cells() map[[2]int]bool {
+	live := make(map[[2]int]bool, h.root.population)
+	h.collect(h.root, h.x, h.y, live)
+	return live
+}
+
+// expand returns a node one level up with n in its middle, surrounded by dead cells.
+func (h *hashlife) expand(n *node) *node {
+	e := h.emptyNode(n.level - 1)
+	return h.join(
+		h.join(e, e, e, n.nw),
+		h.join(e, e, n.ne, e),
+		h.join(e, n.sw, e, e),
+		h.join(n.se, e, e, e),
+	)
+}
+
+// center returns the node one level down in the middle of n.
+func (h *hashlife) center(n *node) *node {
+	return h.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// padded reports whether all of the live cells of n lie in its middle half.
+func padded(n *node) bool {
+	return n.nw.population == n.nw.se.population &&
+		n.ne.population == n.ne.sw.population &&
+		n.sw.population == n.sw.ne.population &&
+		n.se.population == n.se.nw.population
+}
+
+// successor returns the node one level down in the middle of n, advanced by 2^step generations. The step can be
+// at most n.level-2, as cells more than 2^(n.level-2) away from the middle could affect it in more generations.
+func (h *hashlife) successor(n *node, step int) *node {
+	if n.population == 0 {
+		return h.emptyNode(n.level - 1)
+	}
+	key := successorKey{n, step}
+	if result, ok := h.successors[key]; ok {
+		return result
+	}
+
+	var result *node
+	if n.level == 2 {
+		result = h.advanceOnce(n)
+	} else {
+		// Split n into nine overlapping squares, each half its size, and advance them. When taking the biggest step
+		// each square goes half of the way, and the next four squares go the other half. Otherwise the nine squares
+		// only provide their middles and the next four squares take the whole step.
+		nw := n.nw
+		north := h.join(n.nw.ne, n.ne.nw, n.nw.se, n.ne.sw)
+		ne := n.ne
+		west := h.join(n.nw.sw, n.nw.se, n.sw.nw, n.sw.ne)
+		middle := h.center(n)
+		east := h.join(n.ne.sw, n.ne.se, n.se.nw, n.se.ne)
+		sw := n.sw
+		south := h.join(n.sw.ne, n.se.nw, n.sw.se, n.se.sw)
+		se := n.se
+
+		squares := [9]*node{nw, north, ne, west, middle, east, sw, south, se}
+		var parts [9]*node
+		for i, square := range squares {
+			if step == n.level-2 {
+				parts[i] = h.successor(square, step-1)
+			} else {
+				parts[i] = h.center(square)
+			}
+		}
+
+		if step == n.level-2 {
+			step--
+		}
+		result = h.join(
+			h.successor(h.join(parts[0], parts[1], parts[3], parts[4]), step),
+			h.successor(h.join(parts[1], parts[2], parts[4], parts[5]), step),
+			h.successor(h.join(parts[3], parts[4], parts[6], parts[7]), step),
+			h.successor(h.join(parts[4], parts[5], parts[7], parts[8]), step),
+		)
+	}
+
+	h.successors[key] = result
+	return result
+}
+
+// advanceOnce returns the 2x2 middle of a 4x4 node advanced by a single generation, by following the rule.
+func (h *hashlife) advanceOnce(n *node) *node {
+	var grid [4][4]int
+	for i, quadrant := range [4]*node{n.nw, n.ne, n.sw, n.se} {
+		for j, leaf := range [4]*node{quadrant.nw, quadrant.ne, quadrant.sw, quadrant.se} {
+			grid[i%2*2+j%2][i/2*2+j/2] = leaf.population
+		}
+	}
+
+	next := func(x, y int) *node {
+		var liveCount int
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if dx != 0 || dy != 0 {
+					liveCount += grid[x+dx][y+dy]
+				}
+			}
+		}
+
+		if h.rule.NextState(grid[x][y], liveCount) == 1 {
+			return h.alive
+		}
+		return h.dead
+	}
+
+	return h.join(next(1, 1), next(2, 1), next(1, 2), next(2, 2))
+}
+
+// advance moves the board forward by the number of generations, taking the biggest steps it can.
+func (h *hashlife) advance(generations int) {
+	for step := 0; generations > 0; step++ {
+		if generations&(1<<step) == 0 {
+			continue
+		}
+		generations &^= 1 << step
+
+		// Grow the root until its live cells are in the middle half and it is big enough to take the step, then
+		// once more. That leaves enough dead cells around them that nothing can spread out of the successor in
+		// 2^step generations.
+		for h.root.level < step+2 || !padded(h.root) {
+			h.grow()
+		}
+		h.grow()
+
+		quarter := 1 << (h.root.level - 2)
+		h.root = h.successor(h.root, step)
+		h.x += quarter
+		h.y += quarter
+		h.generation += 1 << step
+	}
+}
+
+// grow expands the root by a level, keeping its cells where they are.
+func (h *hashlife) grow() {
+	half := 1 << (h.root.level - 1)
+	h.root = h.expand(h.root)
+	h.x -= half
+	h.y -= half
+}
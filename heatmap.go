@@ -0,0 +1,95 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// heatmap counts how many generations each cell of a board has been alive for, showing where the board is busiest
+// over a long run.
+type heatmap struct {
+	columns, rows int
+	// counts holds the count of each cell, indexed by x*rows+y.
+	counts []int
+}
+
+// recordHeatmap counts the live cells of the board after every generation, if there is a -heatmap file to save the
+// counts to. It returns nil otherwise, which is safe to save.
+func recordHeatmap(board *Board) *heatmap {
+	if cfg.Heatmap == "" {
+		return nil
+	}
+
+	columns, rows := len(board.cells), len(board.cells[0])
+	h := &heatmap{columns: columns, rows: rows, counts: make([]int, columns*rows)}
+	board.OnGeneration(func(b *Board, generation, population int) {
+		for _, c := range b.liveCells() {
+			if c.alive {
+				h.counts[c.x*h.rows+c.y]++
+			}
+		}
+	})
+
+	return h
+}
+
+// save writes the heatmap to the -heatmap file as a PNG image, from black for cells which were never alive through
+// red and yellow to white for the cells which were alive the longest.
+func (h *heatmap) save() {
+	if h == nil {
+		return
+	}
+
+	most := 1
+	for _, count := range h.counts {
+		if count > most {
+			most = count
+		}
+	}
+
+	size := snapshotCellSize(h.columns, h.rows)
+	img := image.NewRGBA(image.Rect(0, 0, h.columns*size, h.rows*size))
+	for x := 0; x < h.columns; x++ {
+		for y := 0; y < h.rows; y++ {
+			fill := heatColor(float64(h.counts[x*h.rows+y]) / float64(most))
+			// y grows upwards on the board but downwards in the image, so the image is filled in upside down.
+			top := (h.rows - 1 - y) * size
+			for py := top; py < top+size; py++ {
+				for px := x * size; px < (x+1)*size; px++ {
+					img.SetRGBA(px, py, fill)
+				}
+			}
+		}
+	}
+
+	f, err := os.Create(cfg.Heatmap)
+	if err != nil {
+		panic(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		panic(err)
+	}
+	if err := f.Close(); err != nil {
+		panic(err)
+	}
+	logInfo("Saved the heatmap to", cfg.Heatmap)
+}
+
+// heatColor returns the color of a cell which was alive for the fraction t of the longest any cell was, between 0 and
+// 1. The red, green and blue channels rise one after the other.
+func heatColor(t float64) color.RGBA {
+	channel := func(from float64) uint8 {
+		v := (t - from) * 3
+		if v < 0 {
+			return 0
+		}
+		if v > 1 {
+			return 0xff
+		}
+		return uint8(v * 0xff)
+	}
+
+	return color.RGBA{channel(0), channel(1.0 / 3), channel(2.0 / 3), 0xff}
+}
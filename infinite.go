@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// How close live cells may get to the edges of the view, in cells, before it grows.
+const viewMargin = 2
+
+// sparseBoard is a board without edges, used with -boundary infinite. Rather than a fixed grid of cells it only
+// stores the coordinates of its live cells, so patterns can travel as far as they like without ever wrapping.
+//
+// The part of the board shown in the window starts out as the area the board was seeded in and grows, keeping its
+// shape, whenever live cells come close to its edges. It never shrinks back.
+type sparseBoard struct {
+	live       map[[2]int]bool
+	generation int
+	rule       Rule
+
+	// The number of cells which came to life and died in the last generation.
+	births int
+	deaths int
+
+	// The view shows the cells from (minX, minY) up to, but not including, (maxX, maxY).
+	minX, minY int
+	maxX, maxY int
+}
+
+// newSparseBoard returns a board without edges, starting with the live cells of b.
+func newSparseBoard(b *Board) *sparseBoard {
+	s := &sparseBoard{
+		live: make(map[[2]int]bool),
+		rule: b.rule,
+		maxX: len(b.cells),
+		maxY: len(b.cells[0]),
+	}
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			if c.alive {
+				s.live[[2]int{c.x, c.y}] = true
+			}
+		}
+	}
+
+	return s
+}
+
+// Step advances the board by one generation.
+//
+// Only live cells and their neighbors can change, so rather than visiting every cell to count its live neighbors,
+// every live cell adds one to the count of each of its neighbors.
+func (s *sparseBoard) Step() {
+	counts := make(map[[2]int]int, len(s.live)*8)
+	for pos := range s.live {
+		// Live cells without any live neighbors still have to be considered for survival.
+		counts[pos] += 0
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if dx != 0 || dy != 0 {
+					counts[[2]int{pos[0] + dx, pos[1] + dy}]++
+				}
+			}
+		}
+	}
+
+	next := make(map[[2]int]bool, len(s.live))
+	s.births = 0
+	for pos, count := range counts {
+		var state int
+		if s.live[pos] {
+			state = 1
+		}
+		if s.rule.NextState(state, count) == 1 {
+			next[pos] = true
+			if !s.live[pos] {
+				s.births++
+			}
+		}
+	}
+	// Every cell which was alive and isn't a survivor has died.
+	s.deaths = len(s.live) - (len(next) - s.births)
+
+	s.live = next
+	s.generation++
+	s.growView()
+}
+
+// growView grows the view until every live cell is at least viewMargin cells away from its edges.
+func (s *sparseBoard) growView() {
+	minX, minY, maxX, maxY := s.minX, s.minY, s.maxX, s.maxY
+	for pos := range s.live {
+		if pos[0]-viewMargin < minX {
+			minX = pos[0] - viewMargin
+		}
+		if pos[1]-viewMargin < minY {
+			minY = pos[1] - viewMargin
+		}
+		if pos[0]+viewMargin+1 > maxX {
+			maxX = pos[0] + viewMargin + 1
+		}
+		if pos[1]+viewMargin+1 > maxY {
+			maxY = pos[1] + viewMargin + 1
+		}
+	}
+	if minX == s.minX && minY == s.minY && maxX == s.maxX && maxY == s.maxY {
+		return
+	}
+
+	// The view keeps its shape so that the cells stay square, which means growing the other axis as well,
+	// evenly on both sides.
+	aspect := float64(s.maxX-s.minX) / float64(s.maxY-s.minY)
+	width, height := maxX-minX, maxY-minY
+	if float64(width)/float64(height) > aspect {
+		extra := int(float64(width)/aspect+0.5) - height
+		minY -= extra / 2
+		maxY += extra - extra/2
+	} else {
+		extra := int(float64(height)*aspect+0.5) - width
+		minX -= extra / 2
+		maxX += extra - extra/2
+	}
+
+	s.minX, s.minY, s.maxX, s.maxY = minX, minY, maxX, maxY
+}
+
+// population returns the number of live cells on the board.
+func (s *sparseBoard) population() int {
+	return len(s.live)
+}
+
+// status returns the status of the board to show while the game runs.
+func (s *sparseBoard) status() status {
+	return status{generation: s.generation, population: s.population(), births: s.births, deaths: s.deaths}
+}
+
+// hash returns a hash of the coordinates of the live cells on the board, sorted by x and then y so that two boards
+// with the same live cells always have the same hash.
+func (s *sparseBoard) hash() uint64 {
+	cells := make([][2]int, 0, len(s.live))
+	for pos := range s.live {
+		cells = append(cells, pos)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i][0] != cells[j][0] {
+			return cells[i][0] < cells[j][0]
+		}
+		return cells[i][1] < cells[j][1]
+	})
+
+	h := fnv.New64a()
+	var buf [16]byte
+	for _, pos := range cells {
+		binary.LittleEndian.PutUint64(buf[:8], uint64(pos[0]))
+		binary.LittleEndian.PutUint64(buf[8:], uint64(pos[1]))
+		h.Write(buf[:])
+	}
+
+	return h.Sum64()
+}
+
+// draw renders the live cells in view. The cells don't have vertex arrays of their own, instead a single square
+// vertex array is scaled to the size of a cell and moved into place for each of them with the cellScale and offset
+// uniforms of the vertex shader.
+func (s *sparseBoard) draw(program, vao uint32) {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	gl.UseProgram(program)
+	gl.BindVertexArray(vao)
+
+	// OpenGL coordinates have a range of 2, between -1 and 1, and the square is 1 wide.
+	cellWidth := 2 / float32(s.maxX-s.minX)
+	cellHeight := 2 / float32(s.maxY-s.minY)
+	shrink := 1 - float32(cfg.Gap)
+	gl.Uniform2f(gl.GetUniformLocation(program, gl.Str("cellScale\x00")), cellWidth*shrink, cellHeight*shrink)
+
+	offset := gl.GetUniformLocation(program, gl.Str("offset\x00"))
+	var drawn int
+	for pos := range s.live {
+		if pos[0] < s.minX || pos[0] >= s.maxX || pos[1] < s.minY || pos[1] >= s.maxY {
+			continue
+		}
+		// With -maxdraw, the cells past the limit are left out.
+		if cfg.MaxDraw > 0 && drawn >= cfg.MaxDraw {
+			break
+		}
+		drawn++
+
+		// The square is centered on the origin, so the offset is the center of the cell.
+		gl.Uniform2f(offset, -1+(float32(pos[0]-s.minX)+0.5)*cellWidth, -1+(float32(pos[1]-s.minY)+0.5)*cellHeight)
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+	}
+}
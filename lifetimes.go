@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// lifetimes counts how many generations in a row cells stay alive for before dying, which says a lot about a rule:
+// under most rules nearly every cell dies young, while the cells of still lifes and oscillators live on and on.
+type lifetimes struct {
+	rows int
+	// streaks holds how many generations each cell has been alive for without a break, 0 for a dead cell, indexed by
+	// x*rows+y.
+	streaks []int
+	// counts holds how many times a cell died after being alive for each number of generations.
+	counts map[int]int
+}
+
+// recordLifetimes keeps track of how long cells stay alive for after every generation, if there is a -lifetimes file
+// to save the counts to. It returns nil otherwise, which is safe to save.
+func recordLifetimes(board *Board) *lifetimes {
+	if cfg.Lifetimes == "" {
+		return nil
+	}
+
+	columns, rows := len(board.cells), len(board.cells[0])
+	l := &lifetimes{rows: rows, streaks: make([]int, columns*rows), counts: make(map[int]int)}
+	// The cells alive at the start have been alive for their first generation already, like cells born later on.
+	for _, c := range board.liveCells() {
+		if c.alive {
+			l.streaks[c.x*rows+c.y] = 1
+		}
+	}
+	last := board.generation
+	board.OnGeneration(func(b *Board, generation, population int) {
+		// A board which is reset starts over, its cells' lives having been cut short rather than come to an end.
+		if generation != last+1 {
+			for i := range l.streaks {
+				l.streaks[i] = 0
+			}
+		}
+		last = generation
+
+		for x := range b.cells {
+			for y, c := range b.cells[x] {
+				i := x*l.rows + y
+				if c.alive {
+					l.streaks[i]++
+				} else if l.streaks[i] > 0 {
+					l.counts[l.streaks[i]]++
+					l.streaks[i] = 0
+				}
+			}
+		}
+	})
+
+	return l
+}
+
+// save writes the lifetimes to the -lifetimes file as a CSV histogram, with a row for each number of generations a
+// cell was alive for and how many times a cell died at that age. Cells still alive at the end haven't finished their
+// lives, so they are left out.
+func (l *lifetimes) save() {
+	if l == nil {
+		return
+	}
+
+	ages := make([]int, 0, len(l.counts))
+	for age := range l.counts {
+		ages = append(ages, age)
+	}
+	sort.Ints(ages)
+
+	f, err := os.Create(cfg.Lifetimes)
+	if err != nil {
+		panic(err)
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"generations", "cells"})
+	for _, age := range ages {
+		w.Write([]string{strconv.Itoa(age), strconv.Itoa(l.counts[age])})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		panic(err)
+	}
+	if err := f.Close(); err != nil {
+		panic(err)
+	}
+	logInfo("Saved the lifetimes to", cfg.Lifetimes)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The -list flags print what can be chosen by name from the same tables the game looks names up in, so the lists are
+// always complete.
+
+// sortedNames returns the names of a table of named things in alphabetical order.
+func sortedNames[V any](table map[string]V) []string {
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// listThemes prints the name of every theme along with its colors, for -list-themes.
+func listThemes() {
+	for _, name := range sortedNames(themes) {
+		t := themes[name]
+		fmt.Printf("%-8s background %v, cell %v, faded %v\n", name, t.background, t.cell, t.faded)
+	}
+}
+
+// listRules prints the name of every rule preset along with the rule in B/S notation, for -list-rules.
+func listRules() {
+	for _, name := range sortedNames(rulePresets) {
+		fmt.Printf("%-9s %v\n", name, rulePresets[name])
+	}
+}
+
+// listPatterns prints the name of every pattern -place knows along with its size, for -list-patterns.
+func listPatterns() {
+	for _, name := range sortedNames(patterns) {
+		p, _, err := parsePlaintext(patterns[name])
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%-12s %dx%d\n", name, len(p), len(p[0]))
+	}
+}
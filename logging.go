@@ -0,0 +1,33 @@
+package main
+
+import "log"
+
+// How much is logged, from nothing but fatal errors with -quiet to diagnostics with -verbose.
+const (
+	levelQuiet = iota
+	levelNormal
+	levelVerbose
+)
+
+var logLevel = levelNormal
+
+// logInfo logs messages the user should see, such as warnings and the result of pressing a key, unless -quiet.
+func logInfo(v ...any) {
+	if logLevel >= levelNormal {
+		log.Println(v...)
+	}
+}
+
+// logInfof is logInfo with a format string.
+func logInfof(format string, v ...any) {
+	if logLevel >= levelNormal {
+		log.Printf(format, v...)
+	}
+}
+
+// logVerbose logs diagnostics which are only of interest with -verbose.
+func logVerbose(v ...any) {
+	if logLevel >= levelVerbose {
+		log.Println(v...)
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
-	"log"
-	"math/rand"
+	"os"
+	"os/signal"
 	"runtime"
+	"runtime/pprof"
 	"strings"
 	"time"
 )
@@ -14,29 +15,79 @@ import (
 const (
 	width     = 500
 	height    = 500
-	rows      = 100
-	columns   = 100
 	threshold = 0.15
-	fps       = 2
+	// Holding the up or down arrow speeds the game up or slows it down by speedStep every time the key repeats,
+	// between minFps and maxFps.
+	speedStep = 1.1
+	minFps    = 0.25
+	maxFps    = 60
+	// Every frame sleeps for at least minSleep, even when it took longer than it should have, so that the main loop
+	// never spins a core flat out. The game runs at maxFps at the most, and slower when frames take longer than
+	// 1/maxFps of a second to step and draw.
+	minSleep = time.Millisecond
+	// The most generations which can go by in a frame, see -steps-per-frame. The keypad * and / double and halve them.
+	maxStepsPerFrame = 4096
+	// Enter steps the game until nothing changes any more, giving up after maxSettle generations of a board which
+	// never settles, such as one with an oscillator or a spaceship on it.
+	maxSettle = 10000
 	// These are strings containing GLSL source code for two shaders, one for a vertex shader and another for a fragment shader.
 	// The only thing special about these strings is that they both end in a null-termination character, \x00 - a requirement for
-	// OpenGL to be able to compile them. Make note of the fragmentShaderSource, this is where we define the color of our shape
+	// OpenGL to be able to compile them - which the fragment shader only gets once the shade function has been added to
+	// it. They start without a #version directive, which compileShader adds for the
+	// OpenGL version of the window. Make note of the fragmentShaderSource, this is where we define the color of our shape
 	// in RGBA format using a vec4. You can change the value here, which is currently RGBA(1, 1, 1, 1) or white, to change the
-	// color of the triangle.
+	// color of the triangle. The color can also be set for each cell through the colour uniform, which is set to the cell color of the -theme.
+	// The alpha is how opaque the shape is, see the blending set up in initOpenGL.
+	//
+	// Every cell is drawn from the six vertices of the square slice, in the same order, so the vertex shader can use
+	// gl_VertexID to look up which corner of the cell it is handling. That corner is passed on to the fragment shader
+	// as local, the position of the fragment within its cell between -1 and 1, which lets it discard everything
+	// outside of a circle when drawing round cells.
+	//
+	// The cellScale and offset uniforms resize and move the vertices, so that a single vertex array can be drawn in
+	// place of any cell. They default to leaving the vertices where they are. The odd uniform tints the cells a little
+	// towards orange, which -parity sets on odd generations to show the phase of oscillators.
+	//
+	// The fragment shader only declares the shade function, which is defined after it by the -cell-shader file or by
+	// defaultShade, see cellFragmentShader. The shaded uniform is only set while the cells are drawn, so that nothing
+	// else drawn with the same program goes through it.
 	vertexShaderSource = `
-    #version 410
     in vec3 vp;
+    uniform vec2 scale;
+    uniform vec2 cellScale = vec2(1, 1);
+    uniform vec2 offset = vec2(0, 0);
+    out vec2 local;
+    const vec2 corners[6] = vec2[6](
+        vec2(-1, 1), vec2(-1, -1), vec2(1, -1),
+        vec2(-1, 1), vec2(1, 1), vec2(1, -1)
+    );
     void main() {
-        gl_Position = vec4(vp, 1.0);
+        local = corners[gl_VertexID % 6];
+        gl_Position = vec4((vp.xy * cellScale + offset) * scale, vp.z, 1.0);
     }
 ` + "\x00"
 	fragmentShaderSource = `
-    #version 410
+    in vec2 local;
+    uniform bool circle;
+    uniform float radius;
+    uniform vec4 colour = vec4(1, 1, 1, 1);
+    uniform bool odd;
+    uniform bool shaded;
     out vec4 frag_colour;
+    vec4 shade(vec4 colour, vec2 local);
     void main() {
-        frag_colour = vec4(1, 1, 1, 1);
+        if (circle && length(local) > radius) {
+            discard;
+        }
+        frag_colour = colour;
+        if (shaded) {
+            frag_colour = shade(colour, local);
+        }
+        if (odd) {
+            frag_colour.rgb = mix(frag_colour.rgb, vec3(1.0, 0.5, 0.1), 0.3);
+        }
     }
-` + "\x00"
+`
 )
 
 // The slice contains 9 values, three for each vertex of a triangle.
@@ -61,80 +112,730 @@ var (
 	}
 )
 
-type cell struct {
-	// A drawable is a square Vertex Array Object.
-	drawable uint32
-
-	alive     bool
-	aliveNext bool
-
-	x int
-	y int
-}
-
 func main() {
 	// Ensures we will always execute in the same operating system thread,
 	// which is important for GLFW which must always be called from the same thread it was initialized on.
 	runtime.LockOSThread()
 
-	window := initGlfw()
-	defer glfw.Terminate()
-	program := initOpenGL()
+	parseFlags()
+	defer startProfiling()()
+
+	// Ctrl-C stops the game like closing the window does, so that everything deferred still gets to finish writing.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	if cfg.Run > 0 {
+		runHeadless(interrupt)
+		return
+	}
+	if cfg.Soups > 0 {
+		runSoups(interrupt)
+		return
+	}
+	if cfg.BenchRender > 0 {
+		benchRender()
+		return
+	}
+
+	renderer := newRenderer()
+	renderer.Init()
+	defer renderer.Close()
+
+	board := newBoard()
+	// With -boards, more boards run side by side from the same starting state, each following its own rule.
+	panes := newPanes(board)
+
+	// With -replay, the board is loaded with the generations of a recording rather than following its rule.
+	var rep *replay
+	if cfg.Replay != "" {
+		var err error
+		if rep, err = openReplay(cfg.Replay, cfg.Columns, cfg.Rows); err != nil {
+			panic(err)
+		}
+		defer rep.close()
+		if err := rep.seek(board, 0); err != nil {
+			panic(err)
+		}
+	}
+
+	// Running on the GPU or on a board without edges draws straight into the window of the OpenGL renderer.
+	glr, _ := renderer.(*glRenderer)
+	// The points renderer only draws the cells its own way, the window is that of the OpenGL renderer it is built on.
+	if points, ok := renderer.(*pointsRenderer); ok {
+		glr = points.glRenderer
+	}
+
+	// A board without edges keeps its live cells in a sparse board instead, drawn with a single vertex array.
+	var sparse *sparseBoard
+	var squareVao uint32
+	if cfg.Boundary == "infinite" {
+		sparse = newSparseBoard(board)
+		squareVao = makeVao(square)
+	}
+
+	var gpu *gpuLife
+	if cfg.GPU {
+		var err error
+		if gpu, err = newGPULife(board); err != nil {
+			logInfo("Falling back to the CPU:", err)
+		}
+	}
+
+	// With -rule-explorer, the board switches to a random rule every so often and whenever N is pressed.
+	var explore func()
+	if cfg.RuleExplorer > 0 {
+		e := newExplorer(board.seed)
+		explore = func() {
+			r := e.next(board)
+			if sparse != nil {
+				sparse = newSparseBoard(board)
+			}
+			if glr != nil {
+				glr.rule = r.String()
+			}
+		}
+		explore()
+	}
+
+	// panesHash returns a hash of the live cells of every pane, which only changes when one of them does.
+	panesHash := func() uint64 {
+		var h uint64
+		for _, b := range panes {
+			h = h*31 ^ b.Hash()
+		}
+		return h
+	}
+	// With -kiosk, the generation at which the boards on the CPU were first in each state since they were last seeded.
+	// Once they come back to one of them, they go round the same cycle forever, like a soup which has settled in
+	// runSoup, and the game starts over.
+	var seen map[uint64]int
+	if cfg.Kiosk {
+		seen = map[uint64]int{panesHash(): board.generation}
+	}
+
+	// restart seeds the board again from a new seed, as R does, and as happens by itself with -kiosk once the board has
+	// settled.
+	restart := func() {
+		seed := newSeed()
+		for _, b := range panes {
+			b.reset(seed)
+		}
+		if seen != nil {
+			seen = map[uint64]int{panesHash(): board.generation}
+		}
+		if sparse != nil {
+			sparse = newSparseBoard(board)
+		}
+		if gpu != nil {
+			gpu.load(board.cells)
+		}
+		// The board is random again, rather than one of the patterns of the tour.
+		if glr != nil {
+			glr.pattern = ""
+		}
+	}
+	// Tab tours the patterns -place knows.
+	patternTour := newTour()
+
+	// While paused, the board is still drawn and can be edited, but no generations go by. With -start-paused the game
+	// starts out paused, so the board can be edited before anything happens.
+	paused := cfg.StartPaused
+	pauseAt := cfg.PauseAt
+	pauseOver := cfg.PauseOver
+	// The number of generations which go by every frame, which can be changed while the game runs.
+	stepsPerFrame := cfg.StepsPerFrame
+	// The number of generations per second, which starts out at -fps and can be changed while the game runs.
+	speed := cfg.FPS
+	// Whether Enter was pressed, to step the game until it settles before the next frame.
+	settle := false
+
+	if glr != nil {
+		window := glr.window
+
+		// Keep the board in proportion with the window, both now and whenever the window changes size.
+		programs := []uint32{glr.program}
+		if gpu != nil {
+			programs = append(programs, gpu.render)
+		}
+		fbWidth, fbHeight := window.GetFramebufferSize()
+		setAspect(programs, fbWidth, fbHeight)
+		window.SetFramebufferSizeCallback(func(w *glfw.Window, width, height int) {
+			gl.Viewport(0, 0, int32(width), int32(height))
+			setAspect(programs, width, height)
+		})
+
+		// Editing changes the cells on the CPU, which aren't used on the GPU or on a board without edges. With -kiosk
+		// there is no cursor to edit with.
+		var e *editor
+		if gpu == nil && sparse == nil && len(panes) == 1 && rep == nil && !cfg.Kiosk {
+			e = &editor{board: board, brush: 1}
+			glr.editor = e
+			window.SetMouseButtonCallback(e.mouseButton)
+			window.SetCursorPosCallback(func(w *glfw.Window, x, y float64) {
+				glr.showTitle()
+			})
+		}
+
+		// Playing back a recording, scrolling down scrubs forwards through it and scrolling up back, a generation a notch
+		// or replayScrollJump with Shift. Trackpads scroll by fractions of a notch, which add up until they make one.
+		if rep != nil {
+			var scrolled float64
+			window.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+				scrolled -= yoff
+				notches := int(scrolled)
+				if notches == 0 {
+					return
+				}
+				scrolled -= float64(notches)
+				if w.GetKey(glfw.KeyLeftShift) == glfw.Press || w.GetKey(glfw.KeyRightShift) == glfw.Press {
+					notches *= replayScrollJump
+				}
+				rep.scrub(board, notches)
+			})
+			logInfo("Playing back", cfg.Replay+", scroll or use the left and right arrows to scrub through it")
+		}
+
+		window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+			// The speed keeps changing for as long as the key is held down, rather than once per press.
+			if (key == glfw.KeyUp || key == glfw.KeyDown) && action != glfw.Release {
+				speed = changeSpeed(speed, key == glfw.KeyUp)
+				return
+			}
+			// Playing back a recording, the left and right arrows scrub back and forth through it in the same way,
+			// by a generation at a time or by replayJump with Shift.
+			if (key == glfw.KeyLeft || key == glfw.KeyRight) && action != glfw.Release && rep != nil {
+				by := 1
+				if mods&glfw.ModShift != 0 {
+					by = replayJump
+				}
+				if key == glfw.KeyLeft {
+					by = -by
+				}
+				rep.scrub(board, by)
+				return
+			}
+			if action != glfw.Press {
+				return
+			}
+
+			switch key {
+			case glfw.KeyW:
+				if sparse != nil {
+					logInfo("A board without edges can't wrap")
+					return
+				}
+				for _, b := range panes {
+					b.setWrap(!b.wrap)
+				}
+				if gpu != nil {
+					gpu.setWrap(board.wrap)
+				}
+				logInfo("Wrapping around the edges:", board.wrap)
+			case glfw.KeyR:
+				// A recording starts over from its first generation instead.
+				if rep != nil {
+					if err := rep.seek(board, 0); err != nil {
+						panic(err)
+					}
+					return
+				}
+				restart()
+			case glfw.KeyQ, glfw.KeyH, glfw.KeyV, glfw.KeyLeftBracket, glfw.KeyRightBracket:
+				if e != nil {
+					e.key(key)
+				}
+			case glfw.KeyI:
+				if e == nil {
+					logInfo("Only boards with edges on the CPU can be inverted")
+					return
+				}
+				board.invert()
+			case glfw.KeyS:
+				if cfg.SVG == "" {
+					logInfo("Give an -svg file to save the board to")
+					return
+				}
+				if err := writeSVG(board, cfg.SVG); err != nil {
+					panic(err)
+				}
+				logInfo("Saved generation", board.generation, "to", cfg.SVG)
+			case glfw.KeyB:
+				if cfg.Kiosk {
+					return
+				}
+				if sparse != nil {
+					logInfo("A board without edges has no seam to show")
+					return
+				}
+				glr.seam = !glr.seam
+			case glfw.KeyN:
+				if explore != nil {
+					explore()
+				}
+			case glfw.KeyC:
+				if cfg.Coloring == "age" {
+					cfg.Coloring = "flat"
+				} else {
+					cfg.Coloring = "age"
+				}
+				logInfo("Coloring:", cfg.Coloring)
+			case glfw.KeyM:
+				if cfg.Noise == 0 {
+					logInfo("Give a -noise chance to switch on")
+					return
+				}
+				for _, b := range panes {
+					if b.noise > 0 {
+						b.noise = 0
+					} else {
+						b.noise = cfg.Noise
+					}
+				}
+				logInfo("Noise:", board.noise > 0)
+			case glfw.KeyKPMultiply, glfw.KeyKPDivide:
+				stepsPerFrame = changeStepsPerFrame(stepsPerFrame, key == glfw.KeyKPMultiply)
+				logInfo("Generations per frame:", stepsPerFrame)
+			case glfw.KeySpace:
+				paused = !paused
+				logInfo("Paused:", paused)
+			case glfw.KeyTab:
+				// The patterns are put on the cells on the CPU, like the editor puts its own.
+				if e == nil {
+					logInfo("Only a single board with edges on the CPU can tour the patterns")
+					return
+				}
+				glr.pattern = patternTour.next(board)
+				// The pattern starts running right away, even if the last one had settled and paused the game.
+				paused = false
+				logInfo("Pattern:", glr.pattern)
+			case glfw.KeyEnter:
+				// Only the boards on the CPU keep track of whether any cell changed, and a recording only plays back.
+				if gpu != nil || sparse != nil || rep != nil {
+					logInfo("Only boards with edges on the CPU can be stepped until they settle")
+					return
+				}
+				settle = true
+			}
+		})
+	}
+
+	stats := openStats()
+	defer stats.Close()
+	recordStats(board, stats)
+	for _, b := range panes {
+		detectSpaceships(b)
+		detectWrapped(b)
+	}
+	recordSnapshots(board)
+	heat := recordHeatmap(board)
+	defer heat.save()
+	lives := recordLifetimes(board)
+	defer lives.save()
+	anim := recordGIF(board)
+	defer anim.save()
+	rec := recordRun(board)
+	defer rec.close()
+	defer saveOnExit(board)
+
+	// step advances whichever board the game runs on by a generation and reports whether any cell changed, which only
+	// the boards on the CPU keep track of.
+	step := func() bool {
+		switch {
+		case rep != nil:
+			return rep.step(board)
+		case gpu != nil:
+			gpu.step()
+			return true
+		case sparse != nil:
+			sparse.Step()
+			if err := stats.write(sparse.generation, sparse.population(), sparse.births, sparse.deaths); err != nil {
+				panic(err)
+			}
+			return true
+		}
 
-	cells := makeCells()
-	for !window.ShouldClose() {
+		var changed bool
+		for _, b := range panes {
+			if b.Step() {
+				changed = true
+			}
+		}
+		return changed
+	}
+	// currentGeneration returns the generation of whichever board the game runs on.
+	currentGeneration := func() int {
+		switch {
+		case gpu != nil:
+			return gpu.generation
+		case sparse != nil:
+			return sparse.generation
+		}
+		return board.generation
+	}
+	// currentPopulation returns the population of whichever board the game runs on, which isn't known on the GPU.
+	currentPopulation := func() int {
+		if sparse != nil {
+			return sparse.population()
+		}
+		return board.Population()
+	}
+	// due reports whether something is to happen at the generation, after which no more generations go by in the same
+	// frame, so that it happens right on time.
+	due := func(generation int) bool {
+		return explore != nil && generation >= cfg.RuleExplorer || pauseAt > 0 && generation >= pauseAt ||
+			cfg.MaxGen > 0 && generation >= cfg.MaxGen
+	}
+
+	// The first -skip generations go by without being drawn, passing over the chaotic start of a random soup.
+	for i := 0; i < cfg.Skip && !interrupted(interrupt); i++ {
+		step()
+	}
+
+	if cfg.FPSUnlimited {
+		logInfo("Running as fast as possible, -fps-unlimited is for measuring rather than watching")
+	}
+	// When the generations per second were last logged with -fps-unlimited, and the generation then.
+	measured, measuredGeneration := time.Now(), currentGeneration()
+
+	for !renderer.ShouldClose() {
 		t := time.Now()
 
-		for x := range cells {
-			for _, c := range cells[x] {
-				c.checkState(cells)
+		// Enter fast-forwards an almost settled pattern to its still life, pausing the game there to look at it.
+		if settle {
+			settle = false
+			start := currentGeneration()
+			if stepUntilSettled(step, maxSettle) {
+				logInfo("Settled into a still life after", currentGeneration()-start, "generations, at generation",
+					currentGeneration())
+			} else {
+				logInfo("Still changing after", maxSettle, "generations, at generation", currentGeneration())
+			}
+			paused = true
+		}
+
+		// With -steps-per-frame, several generations go by between frames to fast-forward the game.
+		for i := 0; i < stepsPerFrame && !paused; i++ {
+			// Once nothing changes any more, every generation to come is the same. The rule explorer moves on to the
+			// next rule and -kiosk starts over from a new seed, otherwise the game pauses until Space is pressed.
+			if !step() {
+				if rep != nil {
+					logInfo("Reached the end of the recording at generation", currentGeneration())
+					paused = true
+					break
+				}
+				logInfo("Settled into a still life at generation", currentGeneration())
+				switch {
+				case explore != nil:
+					explore()
+				case cfg.Kiosk:
+					restart()
+				default:
+					paused = true
+				}
+				break
+			}
+			if seen != nil && explore == nil && gpu == nil && sparse == nil && rep == nil {
+				h := panesHash()
+				if first, ok := seen[h]; ok {
+					logInfo("Settled into a cycle of period", currentGeneration()-first, "at generation", first)
+					restart()
+					break
+				}
+				seen[h] = currentGeneration()
+			}
+			// The game pauses over -pause-over only once, like at -pause-at, so that it carries on when unpaused.
+			if pauseOver > 0 && currentPopulation() > pauseOver {
+				logInfo("Pausing at generation", currentGeneration(), "with a population of", currentPopulation())
+				paused, pauseOver = true, 0
+				break
+			}
+			if due(currentGeneration()) {
+				break
 			}
 		}
 
-		draw(cells, window, program)
+		switch {
+		case gpu != nil:
+			// The population isn't known when running on the GPU, so the title isn't kept up to date.
+			gpu.draw()
+			glr.drawGrid(cfg.Columns, cfg.Rows)
+			glr.drawSeam()
+			glr.present()
+		case sparse != nil:
+			sparse.draw(glr.program, squareVao)
+			glr.drawGrid(sparse.maxX-sparse.minX, sparse.maxY-sparse.minY)
+			glr.finish(sparse.status())
+		case len(panes) > 1:
+			glr.drawPanes(panes)
+		default:
+			renderer.Draw(board)
+		}
+		generation := currentGeneration()
+
+		if explore != nil && generation >= cfg.RuleExplorer {
+			explore()
+		}
+
+		// The game pauses at -pause-at only once, so that it carries on when unpaused.
+		if pauseAt > 0 && generation >= pauseAt {
+			logInfo("Pausing at generation", generation)
+			paused, pauseAt = true, 0
+		}
+		if cfg.MaxGen > 0 && generation >= cfg.MaxGen {
+			logInfo("Stopping at generation", generation)
+			return
+		}
+		if interrupted(interrupt) {
+			logInfo("Interrupted at generation", generation)
+			return
+		}
+
+		// With -fps-unlimited, the next frame starts right away, and the generations which went by are logged once a
+		// second.
+		if cfg.FPSUnlimited {
+			if elapsed := time.Since(measured); elapsed >= time.Second {
+				logInfo(fmt.Sprintf("%.0f generations per second", float64(generation-measuredGeneration)/elapsed.Seconds()))
+				measured, measuredGeneration = time.Now(), generation
+			}
+			continue
+		}
 
 		// reduce the game speed by introducing a frames-per-second limitation in the main loop.
-		// 2 game iterations per second.
-		time.Sleep(time.Second/time.Duration(fps) - time.Since(t))
+		// 2 game iterations per second, unless sped up or slowed down.
+		sleep := time.Duration(float64(time.Second)/speed) - time.Since(t)
+		if sleep < minSleep {
+			sleep = minSleep
+		}
+		time.Sleep(sleep)
+	}
+}
+
+// stepUntilSettled steps the game with step until it reports that nothing changed, at the most limit times, and
+// reports whether it settled.
+func stepUntilSettled(step func() bool, limit int) bool {
+	for i := 0; i < limit; i++ {
+		if !step() {
+			return true
+		}
+	}
+	return false
+}
+
+// changeStepsPerFrame returns twice or half as many generations per frame as steps, between 1 and
+// maxStepsPerFrame.
+func changeStepsPerFrame(steps int, more bool) int {
+	if more {
+		steps *= 2
+	} else {
+		steps /= 2
+	}
+
+	if steps < 1 {
+		return 1
+	}
+	if steps > maxStepsPerFrame {
+		return maxStepsPerFrame
+	}
+	return steps
+}
+
+// changeSpeed returns the number of generations per second one step faster or slower than speed. Each step changes
+// the speed by the same factor, so it ramps up smoothly whether the game is crawling or racing.
+func changeSpeed(speed float64, faster bool) float64 {
+	if faster {
+		speed *= speedStep
+	} else {
+		speed /= speedStep
+	}
+
+	if speed < minFps {
+		return minFps
+	}
+	if speed > maxFps {
+		return maxFps
+	}
+	return speed
+}
+
+// openStats opens the -csv statistics file, returning nil when no file was asked for.
+func openStats() *statsWriter {
+	if cfg.CSV == "" {
+		return nil
+	}
+
+	stats, err := newStatsWriter(cfg.CSV)
+	if err != nil {
+		panic(err)
+	}
+	return stats
+}
+
+// recordStats writes the statistics of every generation of the board to stats, if there is a file to write them to.
+func recordStats(board *Board, stats *statsWriter) {
+	if stats == nil {
+		return
+	}
+
+	board.OnGeneration(func(b *Board, generation, population int) {
+		if err := stats.write(generation, population, b.births, b.deaths); err != nil {
+			panic(err)
+		}
+	})
+}
+
+// status is what is shown of a board while the game runs.
+type status struct {
+	generation int
+	population int
+	// The number of cells which came to life and died in the last generation, showing how much the board is churning.
+	births int
+	deaths int
+}
+
+// statusTitle formats a window title showing the status of the board and the frames per second, the simplest way to
+// display the status of the game while it runs.
+func statusTitle(s status, fps float64) string {
+	return fmt.Sprintf("%s - generation %d, population %d, %d born, %d died, %.1f fps",
+		cfg.Title, s.generation, s.population, s.births, s.deaths, fps)
+}
+
+// startProfiling starts writing a CPU profile when -cpuprofile is given and returns a function to defer which stops it
+// and, when -memprofile is given, writes a heap profile of the program as it is when shutting down.
+func startProfiling() func() {
+	if cfg.CPUProfile != "" {
+		f, err := os.Create(cfg.CPUProfile)
+		if err != nil {
+			panic(err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			panic(err)
+		}
+	}
+
+	return func() {
+		if cfg.CPUProfile != "" {
+			pprof.StopCPUProfile()
+		}
+		if cfg.MemProfile != "" {
+			f, err := os.Create(cfg.MemProfile)
+			if err != nil {
+				panic(err)
+			}
+			defer f.Close()
+
+			// Run a garbage collection first so the profile only shows memory which is still in use.
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				panic(err)
+			}
+		}
 	}
 }
 
-// initGlfw initializes glfw and returns a Window to use.
+// The OpenGL version of the window's context, which the shaders are compiled for, see initGlfw.
+var contextMajor, contextMinor int
+
+// initGlfw initializes glfw and returns a Window to use, with an OpenGL context of the -gl-major and -gl-minor
+// version. When the system doesn't provide that version, it falls back to OpenGL 4.1, which the game was written for.
 func initGlfw() *glfw.Window {
 	if err := glfw.Init(); err != nil {
 		panic(err)
 	}
 
 	glfw.WindowHint(glfw.Resizable, glfw.False)
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	contextMajor, contextMinor = cfg.GLMajor, cfg.GLMinor
+	if cfg.GPU && (contextMajor < 4 || contextMajor == 4 && contextMinor < 3) {
+		// Compute shaders were introduced in OpenGL 4.3.
+		contextMajor, contextMinor = 4, 3
+	}
+	glfw.WindowHint(glfw.ContextVersionMajor, contextMajor)
+	glfw.WindowHint(glfw.ContextVersionMinor, contextMinor)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	// Multisampling renders each pixel several times at slightly different offsets and blends the results,
+	// which smooths out the jagged edges of the cells.
+	if cfg.MSAA > 0 {
+		glfw.WindowHint(glfw.Samples, cfg.MSAA)
+	}
 
 	// Binding the window to our current thread.
-	window, err := glfw.CreateWindow(width, height, "Conway's Game of Life", nil, nil)
+	windowWidth, windowHeight := windowSize()
+	// With -kiosk, the window covers the whole of the primary monitor without a border, in the video mode the monitor
+	// is already in so that it doesn't have to switch modes.
+	var monitor *glfw.Monitor
+	if cfg.Kiosk {
+		monitor = glfw.GetPrimaryMonitor()
+		mode := monitor.GetVideoMode()
+		glfw.WindowHint(glfw.RedBits, mode.RedBits)
+		glfw.WindowHint(glfw.GreenBits, mode.GreenBits)
+		glfw.WindowHint(glfw.BlueBits, mode.BlueBits)
+		glfw.WindowHint(glfw.RefreshRate, mode.RefreshRate)
+		windowWidth, windowHeight = mode.Width, mode.Height
+	}
+	window, err := glfw.CreateWindow(windowWidth, windowHeight, cfg.Title, monitor, nil)
+	if err != nil && cfg.GPU {
+		logInfo("Falling back to the CPU, OpenGL 4.3 is not available:", err)
+		cfg.GPU = false
+		contextMajor, contextMinor = cfg.GLMajor, cfg.GLMinor
+		glfw.WindowHint(glfw.ContextVersionMajor, contextMajor)
+		glfw.WindowHint(glfw.ContextVersionMinor, contextMinor)
+		window, err = glfw.CreateWindow(windowWidth, windowHeight, cfg.Title, monitor, nil)
+	}
+	if err != nil && (contextMajor != 4 || contextMinor != 1) {
+		logInfo(fmt.Sprintf("Falling back to OpenGL 4.1, OpenGL %d.%d is not available:", contextMajor, contextMinor), err)
+		contextMajor, contextMinor = 4, 1
+		glfw.WindowHint(glfw.ContextVersionMajor, contextMajor)
+		glfw.WindowHint(glfw.ContextVersionMinor, contextMinor)
+		window, err = glfw.CreateWindow(windowWidth, windowHeight, cfg.Title, monitor, nil)
+	}
 	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("can't open a window with OpenGL %d.%d: %v", contextMajor, contextMinor, err))
 	}
 	window.MakeContextCurrent()
+	if cfg.Kiosk {
+		window.SetInputMode(glfw.CursorMode, glfw.CursorHidden)
+	}
+
+	// The swap interval is the number of screen refreshes to wait for before swapping buffers,
+	// 1 syncs swapping to the display to prevent tearing and 0 swaps immediately.
+	glfw.SwapInterval(cfg.VSync)
 
 	return window
 }
 
+// windowSize returns the size of the window to open, the biggest which fits in width by height pixels and has the
+// same proportions as the board, so that the cells start out square without empty bars around the board.
+func windowSize() (int, int) {
+	if cfg.Columns > cfg.Rows {
+		return width, height * cfg.Rows / cfg.Columns
+	}
+
+	return width * cfg.Columns / cfg.Rows, height
+}
+
 // initOpenGL initializes OpenGL and returns an intiialized program.
 func initOpenGL() uint32 {
 	if err := gl.Init(); err != nil {
 		panic(err)
 	}
 	version := gl.GoStr(gl.GetString(gl.VERSION))
-	log.Println("OpenGL version", version)
+	logVerbose("OpenGL version", version)
+
+	if cfg.MSAA > 0 {
+		gl.Enable(gl.MULTISAMPLE)
+	}
+	// Blending mixes whatever is drawn with what was already drawn beneath it by the alpha of the colour, so that
+	// anything less than opaque lets the cells beneath it show through.
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 
 	vertexShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
 	if err != nil {
 		panic(err)
 	}
-	fragmentShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
+	fragmentShader, err := compileShader(cellFragmentShader(), gl.FRAGMENT_SHADER)
 	if err != nil {
+		reportCellShader(err)
 		panic(err)
 	}
 
@@ -143,27 +844,137 @@ func initOpenGL() uint32 {
 	gl.AttachShader(prog, vertexShader)
 	gl.AttachShader(prog, fragmentShader)
 	gl.LinkProgram(prog)
+	checkCellShaderLink(prog)
+
+	// Uniforms are values shared by every vertex and fragment of a draw call, so the cell shape only has to be set once.
+	gl.UseProgram(prog)
+	var circle int32
+	if cfg.Shape == "circle" {
+		circle = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(prog, gl.Str("circle\x00")), circle)
+	gl.Uniform1f(gl.GetUniformLocation(prog, gl.Str("radius\x00")), float32(cfg.Fill))
+	cell := currentTheme().cell
+	gl.Uniform4f(gl.GetUniformLocation(prog, gl.Str("colour\x00")), cell[0], cell[1], cell[2], 1)
+
+	// Clearing the window fills it with the background color of the theme.
+	background := currentTheme().background
+	gl.ClearColor(background[0], background[1], background[2], 1)
+
 	return prog
 }
 
-func draw(cells [][]*cell, window *glfw.Window, program uint32) {
+// draw renders every cell of the board with the program.
+func draw(board *Board, program uint32) {
 	// Remove anything from the window that was drawn last frame, giving us a clean slate.
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	drawCells(board, program)
+}
+
+// drawCells renders every cell of the board with the program, over whatever has been drawn already.
+func drawCells(board *Board, program uint32) {
 	gl.UseProgram(program)
 
-	// Loop over each cell and have it draw itself.
-	for x := range cells {
-		for _, c := range cells[x] {
+	// In QuadLife every live cell is tinted with its own color, and under Generations rules every state has its own.
+	// A -colormap other than theme has a color of its own for live cells too.
+	colored := cfg.Automaton == "quadlife" || board.rule.States() > 2 || board.spaceships != nil ||
+		cfg.Colormap != "theme"
+	colour := gl.GetUniformLocation(program, gl.Str("colour\x00"))
+	// With -parity, the cells of odd generations are tinted, but nothing drawn over them afterwards is.
+	if cfg.Parity {
+		odd := gl.GetUniformLocation(program, gl.Str("odd\x00"))
+		gl.Uniform1i(odd, int32(board.generation%2))
+		defer gl.Uniform1i(odd, 0)
+	}
+	// With -cell-shader, the cells go through the shade function it defines, but nothing drawn over them afterwards does.
+	if cfg.CellShader != "" {
+		shaded := gl.GetUniformLocation(program, gl.Str("shaded\x00"))
+		gl.Uniform1i(shaded, 1)
+		defer gl.Uniform1i(shaded, 0)
+	}
+
+	// With -invert-render it is the dead cells which are drawn, all in the same color, so every cell has to be visited.
+	if cfg.InvertRender {
+		for x := range board.cells {
+			for _, c := range board.cells[x] {
+				c.draw()
+			}
+		}
+	} else {
+		// Loop over each live or dying cell and have it draw itself. Dead cells have nothing to draw, so they are
+		// skipped without even being visited. With -maxdraw, the cells past the limit are left out.
+		for i, c := range board.liveCells() {
+			if cfg.MaxDraw > 0 && i >= cfg.MaxDraw {
+				break
+			}
+			if board.wrapped[c] {
+				continue
+			}
+			if colored {
+				rgb := cellColor(c, board.rule.States())
+				if board.spaceships[c] {
+					rgb = spaceshipColor
+				}
+				gl.Uniform4f(colour, rgb[0], rgb[1], rgb[2], 1)
+			}
 			c.draw()
 		}
 	}
 
-	// Check if there were any mouse or keyboard events.
-	glfw.PollEvents()
-	// Buffer swapping is important because GLFW (like many graphics libraries) uses double buffering,
-	// meaning everything you draw is actually drawn to an invisible canvas, and only put onto the
-	// visible canvas when you’re ready - which in this case, is indicated by calling SwapBuffers
-	window.SwapBuffers()
+	if board.ant != nil {
+		gl.Uniform4f(colour, antColor[0], antColor[1], antColor[2], 1)
+		board.cells[board.ant.x][board.ant.y].drawShape()
+		// Cells are drawn in the theme's cell color unless told otherwise.
+		cell := currentTheme().cell
+		gl.Uniform4f(colour, cell[0], cell[1], cell[2], 1)
+	}
+}
+
+// aspectScale returns how much to shrink the board along x and y so that a board of boardWidth by boardHeight cells
+// keeps square cells in a window of windowWidth by windowHeight pixels. The board shrinks along whichever axis the
+// window is too long in, leaving empty bars on either side, and is never stretched.
+func aspectScale(windowWidth, windowHeight, boardWidth, boardHeight int) (float32, float32) {
+	windowAspect := float32(windowWidth) / float32(windowHeight)
+	boardAspect := float32(boardWidth) / float32(boardHeight)
+	if windowAspect > boardAspect {
+		return boardAspect / windowAspect, 1
+	}
+
+	return 1, windowAspect / boardAspect
+}
+
+// setAspect sets the scale uniform of each program to correct the board's aspect ratio for a framebuffer of the given size.
+func setAspect(programs []uint32, width, height int) {
+	// Minimized windows have a framebuffer of size zero, there is nothing to correct.
+	if width == 0 || height == 0 {
+		return
+	}
+
+	scaleX, scaleY := aspectScale(width, height, cfg.Columns, cfg.Rows)
+	for _, prog := range programs {
+		gl.UseProgram(prog)
+		gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("scale\x00")), scaleX, scaleY)
+	}
+}
+
+// cellColor returns the RGB color a live or dying cell is drawn in under a rule with the given number of states.
+// With -coloring flat every cell is in the theme's cell color.
+func cellColor(c *cell, states int) [3]float32 {
+	if cfg.Coloring == "flat" {
+		return currentTheme().cell
+	}
+	if cfg.Automaton == "quadlife" && c.alive {
+		return quadLifePalette[c.color]
+	}
+
+	return stateColor(c.state(), states)
+}
+
+// stateColor returns the RGB color of a cell in the given state of a rule with the given number of states.
+// Live cells are in the first color of the -colormap and dying cells go along it as they get closer to being dead,
+// which by default fades them from the theme's cell color towards its faded color.
+func stateColor(state, states int) [3]float32 {
+	return currentColormap().at(float32(state-1) / float32(states-1))
 }
 
 // makeVao initializes and returns a vertex array from the points provided.
@@ -193,7 +1004,11 @@ func makeVao(points []float32) uint32 {
 // which then determines the color of each fragment (you can just consider a fragment to be a pixel) to be drawn to the screen.
 // The purpose of this function is to receive the shader source code as a string as well as its type,
 // and return a pointer to the resulting compiled shader.
+//
+// The source is compiled as GLSL of the same version as the OpenGL context of the window, so the shaders leave out
+// their #version directive and it is added here.
 func compileShader(source string, shaderType uint32) (uint32, error) {
+	source = fmt.Sprintf("#version %d%d0\n", contextMajor, contextMinor) + source
 	shader := gl.CreateShader(shaderType)
 
 	csources, free := gl.Strs(source)
@@ -210,38 +1025,23 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 		log := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
 
-		return 0, fmt.Errorf("failed to compile %v: %v", source, log)
+		return 0, &shaderError{source: source, log: strings.TrimRight(log, "\x00")}
 	}
 
 	return shader, nil
 }
 
-// makeCells creates and returns a 2D slice of pointers to cell structs.
-// The slice has a length of 'rows', and each row has a length of 'columns'.
-// Each cell in the slice is a new cell struct created using the newCell function.
-// Returns the 2D slice of cell pointers.
-func makeCells() [][]*cell {
-	// use the current time as the randomization seed, giving each game a unique starting state.
-	rand.Seed(time.Now().UnixNano())
-
-	cells := make([][]*cell, rows, rows)
-	for x := 0; x < rows; x++ {
-		for y := 0; y < columns; y++ {
-			c := newCell(x, y)
-
-			// set cells alive state equal to the result of a random float, between 0.0 and 1.0,
-			// being less than threshold (0.15). Each cell has a 15% chance of starting out alive.
-			c.alive = rand.Float64() < threshold
-			c.aliveNext = c.alive
-
-			cells[x] = append(cells[x], c)
-		}
-	}
-
-	return cells
+// makeDrawable creates the Vertex Array Object used to draw the cell, sized and positioned to fill its place on the board.
+func (c *cell) makeDrawable() {
+	// After all the points have been scaled and positioned, we set the drawable field equal to
+	// a Vertex Array Object created from them.
+	c.drawable = makeVao(cellPoints(c.x, c.y))
 }
 
-func newCell(x, y int) *cell {
+// cellPoints returns the vertices of the square of the cell at (x, y), in OpenGL coordinates before correcting for
+// the aspect ratio of the window. Cells are 1/columns of the board wide and 1/rows of it tall, so on a board which isn't
+// square they aren't either until setAspect shrinks the board to the right proportions.
+func cellPoints(x, y int) []float32 {
 	// Create a copy of our square definition. This allows us to change its contents to customize
 	// the current cell’s position, without impacting any other cells that are also using the square slice.
 	points := make([]float32, len(square), len(square))
@@ -256,10 +1056,10 @@ func newCell(x, y int) *cell {
 		var size float32
 		switch i % 3 {
 		case 0:
-			size = 1.0 / float32(columns)
+			size = 1.0 / float32(cfg.Columns)
 			position = float32(x) * size
 		case 1:
-			size = 1.0 / float32(rows)
+			size = 1.0 / float32(cfg.Rows)
 			position = float32(y) * size
 		default:
 			continue
@@ -271,91 +1071,98 @@ func newCell(x, y int) *cell {
 		// If the position is greater than or equal to zero, we do the same thing but add the size we calculated.
 		// The purpose of this is to set the scale of each cell so that it fills only its percentage of the game board.
 		// Since we have 10 rows and 10 columns, each cell will be given 10% of the width and 10% of the height of the game board.
+		//
+		// A gap shrinks the cell by moving each edge inwards by half of the gap, leaving a gutter between neighbors.
+		inset := size * float32(cfg.Gap) / 2
 		if points[i] < 0 {
-			points[i] = (position * 2) - 1
+			points[i] = ((position + inset) * 2) - 1
 		} else {
-			points[i] = ((position + size) * 2) - 1
+			points[i] = ((position + size - inset) * 2) - 1
 		}
 	}
 
-	// After all the points have been scaled and positioned, we create a cell with the X and Y coordinate provided,
-	// and set the drawable field equal to a Vertex Array Object created from the points slice we just manipulated.
-	return &cell{
-		drawable: makeVao(points),
-
-		x: x,
-		y: y,
-	}
+	return points
 }
 
-// Each cell needs to know how to draw itself.
+// Each cell needs to know how to draw itself. Dead cells are left out, unless -invert-render is given, when it is the
+// live and dying cells which are left out instead.
 func (c *cell) draw() {
-	if !c.alive {
+	if (c.state() == 0) != cfg.InvertRender {
 		return
 	}
 
-	gl.BindVertexArray(c.drawable)
-	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+	c.drawShape()
 }
 
-// checkState determines the state of the cell for the next tick of the game.
-func (c *cell) checkState(cells [][]*cell) {
-	c.alive = c.aliveNext
-	c.aliveNext = c.alive
-
-	liveCount := c.liveNeighbors(cells)
-	if c.alive {
-		// 1. Any live cell with fewer than two live neighbours dies, as if caused by underpopulation.
-		if liveCount < 2 {
-			c.aliveNext = false
-		}
+// drawShape draws the cell's shape whatever its state, in the current colour.
+func (c *cell) drawShape() {
+	// The vertex array is only made the first time the cell is drawn, as 0 is never the name of one.
+	if c.drawable == 0 {
+		c.makeDrawable()
+	}
 
-		// 2. Any live cell with two or three live neighbours lives on to the next generation.
-		if liveCount == 2 || liveCount == 3 {
-			c.aliveNext = true
-		}
+	gl.BindVertexArray(c.drawable)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+}
 
-		// 3. Any live cell with more than three live neighbours dies, as if by overpopulation.
-		if liveCount > 3 {
-			c.aliveNext = false
-		}
-	} else {
-		// 4. Any dead cell with exactly three live neighbours becomes a live cell, as if by reproduction.
-		if liveCount == 3 {
-			c.aliveNext = true
-		}
+// interrupted reports whether Ctrl-C has been pressed, without waiting for it.
+func interrupted(interrupt <-chan os.Signal) bool {
+	select {
+	case <-interrupt:
+		return true
+	default:
+		return false
 	}
 }
 
-// liveNeighbors returns the number of live neighbors for a cell.
-func (c *cell) liveNeighbors(cells [][]*cell) int {
-	var liveCount int
-	add := func(x, y int) {
-		// If we're at an edge, check the other side of the board.
-		if x == len(cells) {
-			x = 0
-		} else if x == -1 {
-			x = len(cells) - 1
+// runHeadless simulates -run generations without opening a window, then prints the population and hash of the board.
+// When interrupted it stops early, printing the generation it got to.
+func runHeadless(interrupt <-chan os.Signal) {
+	stats := openStats()
+	defer stats.Close()
+
+	board := newBoard()
+	recordStats(board, stats)
+	detectSpaceships(board)
+	detectWrapped(board)
+	recordSnapshots(board)
+	heat := recordHeatmap(board)
+	defer heat.save()
+	lives := recordLifetimes(board)
+	defer lives.save()
+	anim := recordGIF(board)
+	defer anim.save()
+	rec := recordRun(board)
+	defer rec.close()
+	defer saveOnExit(board)
+	if cfg.Boundary == "infinite" {
+		sparse := newSparseBoard(board)
+		if cfg.Hashlife {
+			// Hashlife skips over most generations, so there are no statistics for the ones in between.
+			h := newHashlife(sparse.rule, sparse.live)
+			h.advance(cfg.Run)
+			sparse.live, sparse.generation = h.cells(), h.generation
 		}
-		if y == len(cells[x]) {
-			y = 0
-		} else if y == -1 {
-			y = len(cells[x]) - 1
+		for sparse.generation < cfg.Run && !interrupted(interrupt) {
+			sparse.Step()
+			if err := stats.write(sparse.generation, sparse.population(), sparse.births, sparse.deaths); err != nil {
+				panic(err)
+			}
 		}
 
-		if cells[x][y].alive {
-			liveCount++
-		}
+		fmt.Printf("generation %d population %d hash %016x\n", sparse.generation, sparse.population(), sparse.hash())
+		return
 	}
 
-	add(c.x-1, c.y)   // To the left
-	add(c.x+1, c.y)   // To the right
-	add(c.x, c.y+1)   // up
-	add(c.x, c.y-1)   // down
-	add(c.x-1, c.y+1) // top-left
-	add(c.x+1, c.y+1) // top-right
-	add(c.x-1, c.y-1) // bottom-left
-	add(c.x+1, c.y-1) // bottom-right
+	// A board loaded with -load carries on for -run generations from the one it was saved at.
+	for end := board.generation + cfg.Run; board.generation < end && !interrupted(interrupt); {
+		board.Step()
+	}
+	if cfg.SVG != "" {
+		if err := writeSVG(board, cfg.SVG); err != nil {
+			panic(err)
+		}
+	}
 
-	return liveCount
+	fmt.Printf("generation %d population %d hash %016x\n", board.generation, board.Population(), board.Hash())
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// withConfig runs f with cfg changed by set, putting it back afterwards.
+func withConfig(t *testing.T, set func(c *config)) {
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	set(&cfg)
+}
+
+// extents returns the smallest and biggest x and y of the vertices of a cell, as given by cellPoints.
+func extents(points []float32) (minX, maxX, minY, maxY float32) {
+	minX, minY = math.MaxFloat32, math.MaxFloat32
+	maxX, maxY = -math.MaxFloat32, -math.MaxFloat32
+	for i := 0; i < len(points); i += 3 {
+		x, y := points[i], points[i+1]
+		minX, maxX = min32(minX, x), max32(maxX, x)
+		minY, maxY = min32(minY, y), max32(maxY, y)
+	}
+	return minX, maxX, minY, maxY
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+// TestCellPointsNonSquare checks that on a board of 200 columns by 50 rows, every cell is 1/200 of the board wide and
+// 1/50 of it tall, and that the window and the aspect correction make them square on screen.
+func TestCellPointsNonSquare(t *testing.T) {
+	withConfig(t, func(c *config) {
+		c.Columns, c.Rows, c.Gap = 200, 50, 0
+	})
+
+	for _, tc := range []struct {
+		x, y                   int
+		minX, maxX, minY, maxY float32
+	}{
+		{0, 0, -1, -0.99, -1, -0.96},
+		{199, 49, 0.99, 1, 0.96, 1},
+		{100, 25, 0, 0.01, 0, 0.04},
+		{3, 40, -0.97, -0.96, 0.6, 0.64},
+	} {
+		minX, maxX, minY, maxY := extents(cellPoints(tc.x, tc.y))
+		if !near(minX, tc.minX) || !near(maxX, tc.maxX) {
+			t.Errorf("cell (%d, %d) spans x %v to %v, want %v to %v", tc.x, tc.y, minX, maxX, tc.minX, tc.maxX)
+		}
+		if !near(minY, tc.minY) || !near(maxY, tc.maxY) {
+			t.Errorf("cell (%d, %d) spans y %v to %v, want %v to %v", tc.x, tc.y, minY, maxY, tc.minY, tc.maxY)
+		}
+	}
+
+	windowWidth, windowHeight := windowSize()
+	if windowWidth != 500 || windowHeight != 125 {
+		t.Fatalf("window is %d by %d, want 500 by 125", windowWidth, windowHeight)
+	}
+	if scaleX, scaleY := aspectScale(windowWidth, windowHeight, cfg.Columns, cfg.Rows); scaleX != 1 || scaleY != 1 {
+		t.Errorf("window of the board's own proportions is scaled by %v, %v, want 1, 1", scaleX, scaleY)
+	}
+	// A square window shrinks the board to a quarter of its height, leaving the cells square.
+	if scaleX, scaleY := aspectScale(500, 500, cfg.Columns, cfg.Rows); scaleX != 1 || scaleY != 0.25 {
+		t.Errorf("square window is scaled by %v, %v, want 1, 0.25", scaleX, scaleY)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// The most boards -boards can put side by side before they get too narrow to make out.
+const maxPanes = 4
+
+// paneRules returns the rule of each of the -boards boards. -rule can hold one rule per board, separated by commas,
+// and boards without a rule of their own follow the last one given.
+func paneRules() []string {
+	rules := strings.Split(cfg.Rule, ",")
+	for len(rules) < cfg.Boards {
+		rules = append(rules, rules[len(rules)-1])
+	}
+
+	return rules
+}
+
+// newPanes returns board followed by the rest of the -boards boards, each starting from the same seed as board but
+// following its own rule, so they can be compared side by side.
+func newPanes(board *Board) []*Board {
+	panes := []*Board{board}
+	for _, name := range paneRules()[1:] {
+		r, err := newRule(name)
+		if err != nil {
+			panic(err)
+		}
+		panes = append(panes, newSeededBoard(r, board.seed))
+	}
+
+	return panes
+}
+
+// drawPanes draws the boards side by side, each in its own slice of the window. The viewport maps OpenGL coordinates
+// onto a part of the window, so every board is drawn just as it would be on its own and lands in its pane.
+func (r *glRenderer) drawPanes(boards []*Board) {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	fbWidth, fbHeight := r.window.GetFramebufferSize()
+	paneWidth := fbWidth / len(boards)
+	setAspect([]uint32{r.program}, paneWidth, fbHeight)
+	for i, board := range boards {
+		gl.Viewport(int32(i*paneWidth), 0, int32(paneWidth), int32(fbHeight))
+		drawCells(board, r.program)
+		r.drawSeam()
+	}
+	gl.Viewport(0, 0, int32(fbWidth), int32(fbHeight))
+
+	// The status and graph follow the first board.
+	r.finish(boards[0].status())
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// patterns are the patterns -place knows by name, in the plaintext format read by parsePlaintext.
+var patterns = map[string]string{
+	"block": `
+OO
+OO`,
+	"blinker": `
+OOO`,
+	"glider": `
+.O.
+..O
+OOO`,
+	"lwss": `
+.O..O
+O....
+O...O
+OOOO.`,
+	"pulsar": `
+..OOO...OOO..
+.............
+O....O.O....O
+O....O.O....O
+O....O.O....O
+..OOO...OOO..
+.............
+..OOO...OOO..
+O....O.O....O
+O....O.O....O
+O....O.O....O
+.............
+..OOO...OOO..`,
+	"r-pentomino": `
+.OO
+OO.
+.O.`,
+	"gosper-gun": `
+........................O...........
+......................O.O...........
+............OO......OO............OO
+...........O...O....OO............OO
+OO........O.....O...OO..............
+OO........O...O.OO....O.O...........
+..........O.....O.......O...........
+...........O...O....................
+............OO......................`,
+}
+
+// placeList is the value of the -place flag, which can be given any number of times. Each placement is kept as it was
+// given, a pattern followed by @ and the x and y of the cell its top left corner goes on, like glider@10,10, or just
+// a pattern to put in the middle of the board.
+type placeList []string
+
+func (l *placeList) String() string {
+	return strings.Join(*l, " ")
+}
+
+// Set adds the placements in s, which is usually one but can be several separated by spaces, as String returns them.
+func (l *placeList) Set(s string) error {
+	for _, p := range strings.Fields(s) {
+		if _, err := parsePlacement(p); err != nil {
+			return err
+		}
+		*l = append(*l, p)
+	}
+	return nil
+}
+
+// placement is a pattern to stamp onto the board with -place, with the cell its top left corner goes on.
+type placement struct {
+	pattern pattern
+	// The QuadLife color of each cell of the pattern, indexed like it, see parsePlaintext.
+	colors [][]uint8
+	x, y   int
+	// centered puts the pattern in the middle of the board instead, see center.
+	centered bool
+}
+
+// parsePlacement splits a placement into its pattern and the cell its top left corner goes on. The pattern is one of
+// patterns or else the path of a plaintext pattern file.
+func parsePlacement(s string) (placement, error) {
+	name, at, hasAt := strings.Cut(s, "@")
+	var x, y int
+	if hasAt {
+		xs, ys, ok := strings.Cut(at, ",")
+		if !ok {
+			return placement{}, fmt.Errorf("placement %q should be a pattern, optionally followed by @x,y", s)
+		}
+		var err error
+		if x, err = strconv.Atoi(xs); err != nil {
+			return placement{}, fmt.Errorf("placement %q: %v", s, err)
+		}
+		if y, err = strconv.Atoi(ys); err != nil {
+			return placement{}, fmt.Errorf("placement %q: %v", s, err)
+		}
+	}
+
+	text, ok := patterns[name]
+	if !ok {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return placement{}, fmt.Errorf("placement %q is neither a known pattern nor a file: %v", s, err)
+		}
+		text = string(data)
+	}
+	p, colors, err := parsePlaintext(text)
+	if err != nil {
+		return placement{}, fmt.Errorf("placement %q: %v", s, err)
+	}
+	return placement{pattern: p, colors: colors, x: x, y: y, centered: !hasAt}, nil
+}
+
+// center returns the cell the top left corner of the pattern goes on for it to sit in the middle of the board. A
+// pattern bigger than the board hangs over its edges by the same amount on either side, to be cut off there. When
+// the pattern and the board differ in size by an odd number of cells, so that it can't be exactly in the middle, the
+// pattern sits half a cell to the left of and below it, whether it fits on the board or not.
+func center(p pattern, cells [][]*cell) (int, int) {
+	width, height := len(p), len(p[0])
+	left := floorHalf(len(cells) - width)
+	bottom := floorHalf(len(cells[0]) - height)
+
+	// y grows upwards, so the top row is at the highest y.
+	return left, bottom + height - 1
+}
+
+// floorHalf halves n, rounding down rather than towards zero, so that odd negative numbers round the same way as odd
+// positive ones.
+func floorHalf(n int) int {
+	return n >> 1
+}
+
+// stamp brings to life the live cells of the pattern, with the top left corner of the pattern on the cell at
+// (left, top), in the QuadLife colors given, if any. Cells which are alive already stay alive, and whatever falls
+// outside the board is cut off.
+func stamp(cells [][]*cell, p pattern, colors [][]uint8, left, top int) {
+	bottom := top - (len(p[0]) - 1)
+	for px := range p {
+		for py, alive := range p[px] {
+			x, y := left+px, bottom+py
+			if !alive || x < 0 || x >= len(cells) || y < 0 || y >= len(cells[x]) {
+				continue
+			}
+			cells[x][y].alive = true
+			cells[x][y].aliveNext = true
+			if colors != nil {
+				cells[x][y].color = colors[px][py]
+			}
+		}
+	}
+}
+
+// parsePlaintext reads a pattern in the plaintext format, one line per row from top to bottom with O for a live cell
+// and . for a dead one. Lines starting with ! are comments, and rows shorter than the longest are padded with dead
+// cells.
+//
+// Patterns for QuadLife can give the color of each live cell with A, B, C or D in place of O, for the first to the
+// fourth color of quadLifePalette. The colors are returned alongside the pattern, indexed like it, and cells written as
+// O get the first color.
+func parsePlaintext(text string) (pattern, [][]uint8, error) {
+	var lines []string
+	width := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "!") {
+			continue
+		}
+		lines = append(lines, line)
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, nil, fmt.Errorf("the pattern has no cells")
+	}
+
+	p := make(pattern, width)
+	colors := make([][]uint8, width)
+	for x := range p {
+		p[x] = make([]bool, len(lines))
+		colors[x] = make([]uint8, len(lines))
+	}
+	for row, line := range lines {
+		for x, r := range line {
+			// y grows upwards, so the top row is at the highest y.
+			y := len(lines) - 1 - row
+			switch {
+			case r == 'O' || r == '*':
+				p[x][y] = true
+			case r >= 'A' && r < 'A'+quadLifeColors:
+				p[x][y] = true
+				colors[x][y] = uint8(r - 'A')
+			case r == '.':
+			default:
+				return nil, nil, fmt.Errorf("unexpected %q in row %d of the pattern", r, row+1)
+			}
+		}
+	}
+	return p, colors, nil
+}
+
+// stampPlacements brings to life the cells of every -place pattern, in the QuadLife colors the pattern gives them.
+// Cells which are alive already stay alive, so overlapping patterns are combined, and whatever falls outside the board
+// is cut off.
+func stampPlacements(cells [][]*cell) {
+	for _, s := range cfg.Place {
+		pl, err := parsePlacement(s)
+		if err != nil {
+			panic(err)
+		}
+
+		if pl.centered {
+			pl.x, pl.y = center(pl.pattern, cells)
+		}
+		stamp(cells, pl.pattern, pl.colors, pl.x, pl.y)
+	}
+}
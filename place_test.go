@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+// TestCenterParity centers patterns of even and odd sizes on boards of even and odd sizes, including patterns bigger
+// than the board, checking the corner center returns and that an odd difference in size leaves the extra cell to the
+// right of and above the pattern.
+func TestCenterParity(t *testing.T) {
+	for _, tc := range []struct {
+		width, height, columns, rows int
+		left, top                    int
+	}{
+		{2, 2, 10, 10, 4, 5},
+		{3, 3, 11, 11, 4, 6},
+		{3, 3, 10, 10, 3, 5},
+		{2, 2, 11, 11, 4, 5},
+		{4, 3, 10, 11, 3, 6},
+		{1, 1, 1, 1, 0, 0},
+		{14, 14, 10, 10, -2, 11},
+		{13, 13, 10, 10, -2, 10},
+		{5, 12, 8, 9, 1, 9},
+	} {
+		p := make(pattern, tc.width)
+		for x := range p {
+			p[x] = make([]bool, tc.height)
+		}
+		cells := SeededBoard(tc.rows, tc.columns, 0, 1).cells
+
+		left, top := center(p, cells)
+		if left != tc.left || top != tc.top {
+			t.Errorf("%d by %d pattern on %d by %d board: corner at (%d, %d), want (%d, %d)",
+				tc.width, tc.height, tc.columns, tc.rows, left, top, tc.left, tc.top)
+			continue
+		}
+
+		// The margins are negative where the pattern hangs over the edges of the board.
+		leftMargin, rightMargin := left, tc.columns-(left+tc.width)
+		bottomMargin, topMargin := top-(tc.height-1), tc.rows-1-top
+		wantRight, wantTop := leftMargin, bottomMargin
+		if (tc.columns-tc.width)%2 != 0 {
+			wantRight++
+		}
+		if (tc.rows-tc.height)%2 != 0 {
+			wantTop++
+		}
+		if rightMargin != wantRight || topMargin != wantTop {
+			t.Errorf("%d by %d pattern on %d by %d board: margins left %d, right %d, bottom %d, top %d",
+				tc.width, tc.height, tc.columns, tc.rows, leftMargin, rightMargin, bottomMargin, topMargin)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+const (
+	// The vertex shader of the points renderer places a point at the center of each cell, given in the same OpenGL
+	// coordinates as the vertices of the cells' squares, and sizes it to cover the cell. Its colour is passed on to the
+	// fragment shader as it is.
+	pointsVertexShaderSource = `
+    in vec2 position;
+    in vec3 colour;
+    uniform vec2 scale;
+    uniform float pointSize;
+    out vec3 pointColour;
+    void main() {
+        pointColour = colour;
+        gl_Position = vec4(position * scale, 0.0, 1.0);
+        gl_PointSize = pointSize;
+    }
+` + "\x00"
+	// The fragment shader of the points renderer colours every fragment of a point, cutting round cells out of it with
+	// gl_PointCoord, the position of the fragment within its point between 0 and 1. Odd generations are tinted like
+	// they are by the fragment shader of the cells.
+	pointsFragmentShaderSource = `
+    in vec3 pointColour;
+    uniform bool circle;
+    uniform float radius;
+    uniform bool odd;
+    out vec4 frag_colour;
+    void main() {
+        if (circle && length(gl_PointCoord * 2.0 - 1.0) > radius) {
+            discard;
+        }
+        frag_colour = vec4(pointColour, 1.0);
+        if (odd) {
+            frag_colour.rgb = mix(frag_colour.rgb, vec3(1.0, 0.5, 0.1), 0.3);
+        }
+    }
+` + "\x00"
+)
+
+// The number of floats a point takes up in the vertex buffer: its x and y and the red, green and blue of its colour.
+const pointFloats = 5
+
+// pointsRenderer draws the board in an OpenGL window like glRenderer, but with each live cell as a single point
+// rather than a square of two triangles. The points of every cell are written into one vertex buffer and drawn with a
+// single call every frame, the cheapest way there is of drawing a cell, which makes it the renderer for boards so big
+// that their cells are hardly bigger than a pixel.
+//
+// Everything other than the cells of the board, from the editor's preview to the population graph, is drawn by the
+// glRenderer it is built on.
+type pointsRenderer struct {
+	*glRenderer
+
+	program  uint32
+	vao, vbo uint32
+	// The points drawn last frame, kept around so that their memory is reused from one frame to the next.
+	vertices []float32
+}
+
+func (r *pointsRenderer) Init() {
+	r.glRenderer.Init()
+	// Without this, gl_PointSize is ignored and every point is a single pixel.
+	gl.Enable(gl.PROGRAM_POINT_SIZE)
+
+	vertexShader, err := compileShader(pointsVertexShaderSource, gl.VERTEX_SHADER)
+	if err != nil {
+		panic(err)
+	}
+	fragmentShader, err := compileShader(pointsFragmentShaderSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		panic(err)
+	}
+	r.program = gl.CreateProgram()
+	gl.AttachShader(r.program, vertexShader)
+	gl.AttachShader(r.program, fragmentShader)
+	gl.BindAttribLocation(r.program, 0, gl.Str("position\x00"))
+	gl.BindAttribLocation(r.program, 1, gl.Str("colour\x00"))
+	gl.LinkProgram(r.program)
+
+	gl.UseProgram(r.program)
+	var circle int32
+	if cfg.Shape == "circle" {
+		circle = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(r.program, gl.Str("circle\x00")), circle)
+	gl.Uniform1f(gl.GetUniformLocation(r.program, gl.Str("radius\x00")), float32(cfg.Fill))
+
+	// Unlike the vertex arrays of the cells, the buffer is filled again every frame with whichever cells are alive.
+	gl.GenBuffers(1, &r.vbo)
+	gl.GenVertexArrays(1, &r.vao)
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 4*pointFloats, nil)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 4*pointFloats, gl.PtrOffset(4*2))
+}
+
+func (r *pointsRenderer) Draw(board *Board) {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	r.drawPoints(board)
+	r.drawGrid(cfg.Columns, cfg.Rows)
+	if r.editor != nil {
+		r.editor.drawGhost(r.window, r.glRenderer.program)
+	}
+	r.drawSeam()
+	r.finish(board.status())
+}
+
+// drawPoints draws every live or dying cell of the board, and the ant if there is one, as a point in its color.
+func (r *pointsRenderer) drawPoints(board *Board) {
+	// The points are sized to the cells as they are on screen, which depends on the size of the window.
+	width, height := r.window.GetFramebufferSize()
+	if width == 0 || height == 0 {
+		return
+	}
+	columns, rows := len(board.cells), len(board.cells[0])
+	scaleX, scaleY := aspectScale(width, height, columns, rows)
+	pointSize := float32(width) * scaleX / float32(columns)
+	if cfg.Gap > 0 {
+		pointSize *= float32(1 - cfg.Gap)
+	}
+
+	r.vertices = r.vertices[:0]
+	add := func(c *cell, rgb [3]float32) {
+		x := (float32(c.x)+0.5)/float32(columns)*2 - 1
+		y := (float32(c.y)+0.5)/float32(rows)*2 - 1
+		r.vertices = append(r.vertices, x, y, rgb[0], rgb[1], rgb[2])
+	}
+	if cfg.InvertRender {
+		// The dead cells are drawn instead, all in the cell color of the theme, leaving gaps for the live ones.
+		for x := range board.cells {
+			for _, c := range board.cells[x] {
+				if c.state() == 0 {
+					add(c, currentTheme().cell)
+				}
+			}
+		}
+	} else {
+		for i, c := range board.liveCells() {
+			if cfg.MaxDraw > 0 && i >= cfg.MaxDraw {
+				break
+			}
+			if board.wrapped[c] {
+				continue
+			}
+			rgb := cellColor(c, board.rule.States())
+			if board.spaceships[c] {
+				rgb = spaceshipColor
+			}
+			add(c, rgb)
+		}
+	}
+	if board.ant != nil {
+		add(board.cells[board.ant.x][board.ant.y], antColor)
+	}
+	if len(r.vertices) == 0 {
+		return
+	}
+
+	gl.UseProgram(r.program)
+	gl.Uniform2f(gl.GetUniformLocation(r.program, gl.Str("scale\x00")), scaleX, scaleY)
+	gl.Uniform1f(gl.GetUniformLocation(r.program, gl.Str("pointSize\x00")), pointSize)
+	if cfg.Parity {
+		gl.Uniform1i(gl.GetUniformLocation(r.program, gl.Str("odd\x00")), int32(board.generation%2))
+	}
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(r.vertices), gl.Ptr(r.vertices), gl.STREAM_DRAW)
+	gl.DrawArrays(gl.POINTS, 0, int32(len(r.vertices)/pointFloats))
+}
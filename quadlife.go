@@ -0,0 +1,39 @@
+package main
+
+// QuadLife is a variant of the Game of Life where every live cell has one of four colors. Cells live, die and are born
+// exactly as in Conway's game, but a newborn cell takes the color most of its three parents share or, when all three
+// differ, the one color none of them have. Survivors keep their color, so like-colored regions tend to grow together.
+
+// The number of colors a cell can have in QuadLife.
+const quadLifeColors = 4
+
+// quadLifePalette is the RGB color each QuadLife color is drawn in.
+var quadLifePalette = [quadLifeColors][3]float32{
+	{1, 0.25, 0.25}, // red
+	{0.25, 1, 0.25}, // green
+	{0.3, 0.5, 1},   // blue
+	{1, 1, 0.25},    // yellow
+}
+
+// newbornColor returns the QuadLife color of a cell being born from its three live neighbors.
+func (c *cell) newbornColor() uint8 {
+	var counts [quadLifeColors]int
+	for _, n := range c.neighbors {
+		if n.alive {
+			counts[n.color]++
+		}
+	}
+
+	for color, count := range counts {
+		if count >= 2 {
+			return uint8(color)
+		}
+	}
+	for color, count := range counts {
+		if count == 0 {
+			return uint8(color)
+		}
+	}
+
+	return 0
+}
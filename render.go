@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// Renderer shows the board to the user. The game only talks to the renderer through this interface, so it can be
+// drawn in an OpenGL window or as text in the terminal without the game loop knowing the difference.
+type Renderer interface {
+	// Init prepares the renderer, it is called once before anything is drawn.
+	Init()
+	// Draw shows the current generation of the board.
+	Draw(board *Board)
+	// ShouldClose reports whether the user asked to quit.
+	ShouldClose() bool
+	// Close releases whatever Init set up.
+	Close()
+}
+
+// newRenderer returns the renderer picked with -renderer.
+func newRenderer() Renderer {
+	switch cfg.Renderer {
+	case "terminal":
+		return &terminalRenderer{}
+	case "points":
+		return &pointsRenderer{glRenderer: &glRenderer{}}
+	}
+
+	return &glRenderer{}
+}
+
+// The color of the border drawn around the edges of the board, see glRenderer.drawSeam.
+var seamColor = [3]float32{1, 0.6, 0}
+
+// glRenderer draws the board in an OpenGL window, with a vertex array for each cell.
+type glRenderer struct {
+	window  *glfw.Window
+	program uint32
+	// The graph of the population in the corner of the window, nil when -graph is 0, and the last generation added
+	// to it.
+	graph   *populationGraph
+	graphed int
+	// The editor whose clipboard is previewed beneath the cursor, if the board can be edited.
+	editor *editor
+	// seam draws a border around the edges of the board, where it wraps around, toggled with the B key.
+	seam    bool
+	seamVao uint32
+	// The lines between the cells drawn with -grid, made the first time they are drawn.
+	grid gridLines
+
+	// When the status was last shown, to work out the frames per second.
+	last time.Time
+	// The rule shown in the title when it changes while the game runs, see -rule-explorer.
+	rule string
+	// The pattern shown in the title while touring the patterns with Tab, see tour.
+	pattern string
+	// The title showing the status of the board, before anything about the editor is added to it, see showTitle.
+	title string
+}
+
+func (r *glRenderer) Init() {
+	r.window = initGlfw()
+	r.program = initOpenGL()
+	// Nothing but the board is shown with -kiosk.
+	if cfg.Graph > 0 && !cfg.Kiosk {
+		r.graph = newPopulationGraph(cfg.Graph)
+	}
+}
+
+func (r *glRenderer) Draw(board *Board) {
+	draw(board, r.program)
+	r.drawGrid(cfg.Columns, cfg.Rows)
+	if r.editor != nil {
+		r.editor.drawGhost(r.window, r.program)
+	}
+	r.drawSeam()
+	r.finish(board.status())
+}
+
+// finish completes a frame showing a board with the given status, once its cells are drawn.
+func (r *glRenderer) finish(s status) {
+	if r.graph != nil {
+		// The same generation is shown for as long as the game is paused, but only belongs in the graph once.
+		if s.generation != r.graphed {
+			r.graph.record(s.population)
+			r.graphed = s.generation
+		}
+		r.graph.draw()
+	}
+	r.present()
+	r.setStatus(s)
+}
+
+// drawSeam draws the border around the edges of the board when it is toggled on. It is drawn with the cells' program
+// so that it is scaled the same way as the board. Nothing but the board is shown with -kiosk, so it has no seam.
+func (r *glRenderer) drawSeam() {
+	if !r.seam || cfg.Kiosk {
+		return
+	}
+	if r.seamVao == 0 {
+		// Just inside the edges of the board, so the line isn't cut in half by the edges of the window.
+		const edge = 0.998
+		r.seamVao = makeVao([]float32{
+			-edge, edge, 0,
+			-edge, -edge, 0,
+			edge, -edge, 0,
+			edge, edge, 0,
+			-edge, edge, 0,
+		})
+	}
+
+	gl.UseProgram(r.program)
+	colour := gl.GetUniformLocation(r.program, gl.Str("colour\x00"))
+	circle := gl.GetUniformLocation(r.program, gl.Str("circle\x00"))
+	gl.Uniform4f(colour, seamColor[0], seamColor[1], seamColor[2], 1)
+	// Round cells are cut out of their squares by the fragment shader, which would cut away the corners of the line.
+	gl.Uniform1i(circle, 0)
+
+	gl.BindVertexArray(r.seamVao)
+	gl.DrawArrays(gl.LINE_STRIP, 0, 5)
+
+	cell := currentTheme().cell
+	gl.Uniform4f(colour, cell[0], cell[1], cell[2], 1)
+	if cfg.Shape == "circle" {
+		gl.Uniform1i(circle, 1)
+	}
+}
+
+// present shows what was drawn since the last call and handles any input.
+func (r *glRenderer) present() {
+	// Check if there were any mouse or keyboard events.
+	glfw.PollEvents()
+	// Buffer swapping is important because GLFW (like many graphics libraries) uses double buffering,
+	// meaning everything you draw is actually drawn to an invisible canvas, and only put onto the
+	// visible canvas when you’re ready - which in this case, is indicated by calling SwapBuffers
+	r.window.SwapBuffers()
+}
+
+// setStatus shows the status of the board in the window title, along with the frames per second.
+func (r *glRenderer) setStatus(s status) {
+	now := time.Now()
+	var actualFps float64
+	if !r.last.IsZero() {
+		actualFps = 1 / now.Sub(r.last).Seconds()
+	}
+	r.last = now
+
+	r.title = statusTitle(s, actualFps)
+	if r.rule != "" {
+		r.title += ", rule " + r.rule
+	}
+	if r.pattern != "" {
+		r.title += ", pattern " + r.pattern
+	}
+	if cfg.Replay != "" {
+		r.title += ", scroll to scrub"
+	}
+	r.showTitle()
+}
+
+// showTitle sets the window title to the status of the board followed by the size of the editor's brush and the cell
+// beneath the cursor, if there is an editor. It is called again whenever the cursor moves, so the cell is kept up to
+// date between frames.
+func (r *glRenderer) showTitle() {
+	title := r.title
+	if r.editor != nil {
+		title += fmt.Sprintf(", brush %d", r.editor.brush)
+		if x, y, ok := r.editor.cellAt(r.window); ok {
+			state := "dead"
+			if r.editor.board.cells[x][y].alive {
+				state = "alive"
+			}
+			title += fmt.Sprintf(", cell (%d, %d) %s", x, y, state)
+		}
+	}
+	r.window.SetTitle(title)
+}
+
+func (r *glRenderer) ShouldClose() bool {
+	return r.window.ShouldClose()
+}
+
+func (r *glRenderer) Close() {
+	glfw.Terminate()
+}
+
+// terminalRenderer prints the board to the terminal. Every character holds two cells, one above the other, using the
+// upper and lower half block characters, so that the cells come out roughly square. Quit with Ctrl-C.
+type terminalRenderer struct {
+	last time.Time
+}
+
+func (r *terminalRenderer) Init() {
+	// Hide the cursor and clear the screen.
+	fmt.Print("\x1b[?25l\x1b[2J")
+}
+
+func (r *terminalRenderer) Draw(board *Board) {
+	now := time.Now()
+	var actualFps float64
+	if !r.last.IsZero() {
+		actualFps = 1 / now.Sub(r.last).Seconds()
+	}
+	r.last = now
+
+	var b strings.Builder
+	// Move the cursor back to the top left corner and draw over the last generation.
+	b.WriteString("\x1b[H")
+	b.WriteString(statusTitle(board.status(), actualFps))
+	b.WriteString("\x1b[K\n")
+
+	// y grows upwards, so the top row of characters shows the highest two rows of cells.
+	columns, rows := len(board.cells), len(board.cells[0])
+	for y := rows - 1; y >= 0; y -= 2 {
+		for x := 0; x < columns; x++ {
+			top := board.cells[x][y].state() > 0
+			bottom := y > 0 && board.cells[x][y-1].state() > 0
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	os.Stdout.WriteString(b.String())
+}
+
+func (r *terminalRenderer) ShouldClose() bool {
+	return false
+}
+
+func (r *terminalRenderer) Close() {
+	// Show the cursor again.
+	fmt.Print("\x1b[?25h")
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A recording made with -record holds every generation of a board, to be played back with -replay exactly as it was
+// without simulating anything. It starts with recordMagic and the number of columns and rows of the board as little
+// endian uint32s, followed by a frame for each generation in order. A frame packs the cells into bits, 1 for a live
+// cell and 0 for any other, row by row from the bottom left, eight cells to a byte starting from the lowest bit.
+//
+// Every frame is the same size, so the frame of any generation can be read straight from its place in the file, which
+// lets playback scrub back and forth through the recording as quickly as it plays it forwards.
+
+// The bytes a recording starts with, which tell it apart from any other file.
+const recordMagic = "LIFEREC1"
+
+// The size of the header at the start of a recording, before the first frame.
+const recordHeaderSize = len(recordMagic) + 8
+
+// The number of generations the left and right arrows scrub through a recording by while Shift is held down.
+const replayJump = 100
+
+// The number of generations a notch of the scroll wheel scrubs through a recording by while Shift is held down, fewer
+// than the arrows as the wheel is quicker to turn a few notches.
+const replayScrollJump = 10
+
+// frameSize returns the number of bytes a frame of a board of columns by rows cells takes up.
+func frameSize(columns, rows int) int {
+	return (columns*rows + 7) / 8
+}
+
+// packCells packs the cells of the board into frame, which is frameSize bytes long, as a frame of a recording.
+func packCells(b *Board, frame []byte) {
+	for i := range frame {
+		frame[i] = 0
+	}
+	columns := len(b.cells)
+	for _, c := range b.liveCells() {
+		if c.alive {
+			i := c.y*columns + c.x
+			frame[i/8] |= 1 << (i % 8)
+		}
+	}
+}
+
+// unpackCells loads the board with the cells packed into frame by packCells. Any other state than alive, such as
+// dying, isn't kept in a frame, so every other cell is dead.
+func unpackCells(b *Board, frame []byte) {
+	columns := len(b.cells)
+	for x := range b.cells {
+		for y, c := range b.cells[x] {
+			i := y*columns + x
+			c.alive = frame[i/8]&(1<<(i%8)) != 0
+			c.aliveNext = c.alive
+			c.dying, c.dyingNext = 0, 0
+		}
+	}
+	b.liveStale = true
+}
+
+// recorder writes every generation of a board to the -record file.
+type recorder struct {
+	f     *os.File
+	w     *bufio.Writer
+	frame []byte
+}
+
+// recordRun writes the board as it is and then after every generation to the -record file, if there is one. It returns
+// nil otherwise, which is safe to close. A board which is reset goes on being recorded, as if it carried on from its
+// last generation.
+func recordRun(board *Board) *recorder {
+	if cfg.Record == "" {
+		return nil
+	}
+
+	f, err := os.Create(cfg.Record)
+	if err != nil {
+		panic(err)
+	}
+	columns, rows := len(board.cells), len(board.cells[0])
+	r := &recorder{f: f, w: bufio.NewWriter(f), frame: make([]byte, frameSize(columns, rows))}
+	r.w.WriteString(recordMagic)
+	binary.Write(r.w, binary.LittleEndian, [2]uint32{uint32(columns), uint32(rows)})
+
+	r.write(board)
+	board.OnGeneration(func(b *Board, generation, population int) {
+		r.write(b)
+	})
+
+	return r
+}
+
+// write adds a frame of the board to the recording.
+func (r *recorder) write(b *Board) {
+	packCells(b, r.frame)
+	if _, err := r.w.Write(r.frame); err != nil {
+		panic(err)
+	}
+}
+
+// close writes out whatever is left of the recording and closes the file.
+func (r *recorder) close() {
+	if r == nil {
+		return
+	}
+
+	if err := r.w.Flush(); err != nil {
+		panic(err)
+	}
+	if err := r.f.Close(); err != nil {
+		panic(err)
+	}
+	logInfo("Recorded the game to", cfg.Record)
+}
+
+// replay plays back a recording made with -record, by loading its frames onto a board.
+type replay struct {
+	f *os.File
+	// The number of frames in the recording, one more than the last generation in it.
+	frames int
+	frame  []byte
+	// The generation the board was last loaded with.
+	generation int
+}
+
+// openReplay opens the recording at path for playing back on a board of columns by rows cells.
+func openReplay(path string, columns, rows int) (*replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil || string(header[:len(recordMagic)]) != recordMagic {
+		f.Close()
+		return nil, fmt.Errorf("%s isn't a recording made with -record", path)
+	}
+	recordedColumns := int(binary.LittleEndian.Uint32(header[len(recordMagic):]))
+	recordedRows := int(binary.LittleEndian.Uint32(header[len(recordMagic)+4:]))
+	if recordedColumns != columns || recordedRows != rows {
+		f.Close()
+		return nil, fmt.Errorf("%s is of a board of %d by %d cells, expected %d by %d", path, recordedColumns, recordedRows, columns, rows)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r := &replay{f: f, frame: make([]byte, frameSize(columns, rows))}
+	r.frames = int(info.Size()-int64(recordHeaderSize)) / len(r.frame)
+	if r.frames == 0 {
+		f.Close()
+		return nil, fmt.Errorf("%s has no generations in it", path)
+	}
+
+	return r, nil
+}
+
+// seek loads the board with the frame of the generation.
+func (r *replay) seek(b *Board, generation int) error {
+	if generation < 0 || generation >= r.frames {
+		return errors.New("no such generation in the recording")
+	}
+	if _, err := r.f.ReadAt(r.frame, int64(recordHeaderSize+generation*len(r.frame))); err != nil {
+		return err
+	}
+
+	unpackCells(b, r.frame)
+	b.generation = generation
+	r.generation = generation
+
+	return nil
+}
+
+// step loads the board with the next generation of the recording, reporting false once there are no more.
+func (r *replay) step(b *Board) bool {
+	if r.generation+1 >= r.frames {
+		return false
+	}
+	if err := r.seek(b, r.generation+1); err != nil {
+		panic(err)
+	}
+
+	return true
+}
+
+// scrub loads the board with the generation by generations on from the one it was last loaded with, or back when by is
+// negative, stopping at the first and last generations of the recording.
+func (r *replay) scrub(b *Board, by int) {
+	generation := r.generation + by
+	if generation < 0 {
+		generation = 0
+	}
+	if generation >= r.frames {
+		generation = r.frames - 1
+	}
+	if err := r.seek(b, generation); err != nil {
+		panic(err)
+	}
+}
+
+// close closes the recording.
+func (r *replay) close() {
+	if r == nil {
+		return
+	}
+
+	r.f.Close()
+}
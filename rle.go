@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// The longest line written to an RLE file, as the format asks.
+const rleLineLength = 70
+
+// writeRLE writes the live cells of the board to a file at path in the run length encoded format most Life programs
+// read, starting with a #C line for each of the comments.
+//
+// Each row is written from left to right and the rows from top to bottom, as runs of dead cells (b) and live cells
+// (o) preceded by their length when it is more than 1. Rows end with $ and the pattern with !, and dead cells at the
+// end of a row are left out.
+func writeRLE(path string, b *Board, comments []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, comment := range comments {
+		fmt.Fprintf(w, "#C %s\n", comment)
+	}
+	columns, rows := len(b.cells), len(b.cells[0])
+	fmt.Fprintf(w, "x = %d, y = %d, rule = %v\n", columns, rows, b.rule)
+
+	var line []byte
+	// add appends a run of count tags to the line, starting a new line when it would grow too long.
+	add := func(count int, tag byte) {
+		if count == 0 {
+			return
+		}
+		var run []byte
+		if count > 1 {
+			run = strconv.AppendInt(run, int64(count), 10)
+		}
+		run = append(run, tag)
+		if len(line)+len(run) > rleLineLength {
+			w.Write(append(line, '\n'))
+			line = line[:0]
+		}
+		line = append(line, run...)
+	}
+
+	// Rows which end the pattern or are empty are put off until a row with live cells comes along after them.
+	var endedRows int
+	for y := rows - 1; y >= 0; y-- {
+		var dead, alive int
+		for x := 0; x < columns; x++ {
+			if b.cells[x][y].alive {
+				if dead > 0 {
+					add(endedRows, '$')
+					endedRows = 0
+					add(dead, 'b')
+					dead = 0
+				}
+				alive++
+				continue
+			}
+			if alive > 0 {
+				add(endedRows, '$')
+				endedRows = 0
+				add(alive, 'o')
+				alive = 0
+			}
+			dead++
+		}
+		if alive > 0 {
+			add(endedRows, '$')
+			endedRows = 0
+			add(alive, 'o')
+		}
+		endedRows++
+	}
+	add(1, '!')
+	w.Write(append(line, '\n'))
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
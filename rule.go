@@ -0,0 +1,309 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule decides the next state of a cell from its current state and its number of live neighbors. States are numbered
+// as by Generations rules: 0 is dead, 1 is alive and anything above is dying, see rule. Adding an automaton where
+// cells live and die by their neighbor count is a matter of implementing NextState.
+type Rule interface {
+	// NextState returns the state of a cell in the next generation.
+	NextState(current, liveNeighbors int) int
+	// States returns the number of states a cell can be in, 2 when cells are only ever dead or alive.
+	States() int
+}
+
+// Conway is the rule of Conway's Game of Life, B3/S23.
+type Conway struct{}
+
+func (Conway) NextState(current, liveNeighbors int) int {
+	if liveNeighbors == 3 || current == 1 && liveNeighbors == 2 {
+		return 1
+	}
+	return 0
+}
+
+func (Conway) States() int {
+	return 2
+}
+
+func (Conway) String() string {
+	return "B3/S23"
+}
+
+// HighLife is B36/S23, which plays much like Conway's Game of Life but has a small pattern which copies itself.
+type HighLife struct{}
+
+func (HighLife) NextState(current, liveNeighbors int) int {
+	if liveNeighbors == 3 || current == 0 && liveNeighbors == 6 || current == 1 && liveNeighbors == 2 {
+		return 1
+	}
+	return 0
+}
+
+func (HighLife) States() int {
+	return 2
+}
+
+func (HighLife) String() string {
+	return "B36/S23"
+}
+
+// DayAndNight is B3678/S34678. It is symmetric between live and dead cells: inverting a board and then running it
+// gives the same as running it and then inverting it, so patterns of dead cells in a sea of live ones behave just like
+// their live counterparts.
+type DayAndNight struct{}
+
+func (DayAndNight) NextState(current, liveNeighbors int) int {
+	switch liveNeighbors {
+	case 3, 6, 7, 8:
+		return 1
+	case 4:
+		if current == 1 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (DayAndNight) States() int {
+	return 2
+}
+
+func (DayAndNight) String() string {
+	return "B3678/S34678"
+}
+
+// Seeds is B2/S, under which every live cell dies straight away and a dead cell with exactly two live neighbors is
+// born. Nearly any pattern explodes, filling the board with chaos within a few dozen generations, which makes it good
+// for seeing how the game copes with a crowded board.
+type Seeds struct{}
+
+func (Seeds) NextState(current, liveNeighbors int) int {
+	if current == 0 && liveNeighbors == 2 {
+		return 1
+	}
+	return 0
+}
+
+func (Seeds) States() int {
+	return 2
+}
+
+func (Seeds) String() string {
+	return "B2/S"
+}
+
+// rulePresets are the rules which can be given to -rule by name rather than in B/S notation.
+var rulePresets = map[string]Rule{
+	"conway":   Conway{},
+	"highlife": HighLife{},
+	"daynight": DayAndNight{},
+	"seeds":    Seeds{},
+}
+
+// newRule returns the rule named by s, either one of rulePresets or a rule in B/S notation.
+func newRule(s string) (Rule, error) {
+	if r, ok := rulePresets[strings.ToLower(s)]; ok {
+		return r, nil
+	}
+
+	r, err := parseRule(s)
+	if err != nil {
+		return nil, err
+	}
+	if r.birth[0] {
+		return newB0Rule(r), nil
+	}
+	return r, nil
+}
+
+// rule decides which cells are born and which survive, based on their number of live neighbors. It is written in the
+// usual B/S notation: Conway's Game of Life is B3/S23, meaning a dead cell with 3 live neighbors is born and a live
+// cell with 2 or 3 live neighbors survives.
+//
+// Rules of the Generations family add a third part with the number of states a cell can be in, like B2/S/3 for
+// Brian's Brain. Besides dead and alive, the cells then have states-2 dying states: a live cell which doesn't survive
+// spends one generation in each dying state before it is dead. Dying cells don't count as live neighbors and can't
+// be born again until they are dead.
+type rule struct {
+	birth   [9]bool
+	survive [9]bool
+	// The number of states a cell can be in, 2 for rules without dying states.
+	states int
+}
+
+// parseRule parses a rule in B/S notation with an optional number of states, such as B3/S23 or B2/S/3.
+func parseRule(s string) (rule, error) {
+	r := rule{states: 2}
+
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return r, fmt.Errorf("rule %q isn't of the form B3/S23 or B3/S23/3", s)
+	}
+
+	var seenBirth, seenSurvive bool
+	for _, part := range parts[:2] {
+		var counts *[9]bool
+		switch {
+		case strings.HasPrefix(strings.ToUpper(part), "B") && !seenBirth:
+			counts, seenBirth = &r.birth, true
+		case strings.HasPrefix(strings.ToUpper(part), "S") && !seenSurvive:
+			counts, seenSurvive = &r.survive, true
+		default:
+			return r, fmt.Errorf("rule %q needs one B part and one S part", s)
+		}
+
+		for _, digit := range part[1:] {
+			if digit < '0' || digit > '8' {
+				return r, fmt.Errorf("rule %q has neighbor count %q, expected 0 to 8", s, digit)
+			}
+			counts[digit-'0'] = true
+		}
+	}
+
+	if len(parts) == 3 {
+		states, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(parts[2]), "C"))
+		if err != nil || states < 2 || states > 255 {
+			return r, fmt.Errorf("rule %q has %q states, expected 2 to 255", s, parts[2])
+		}
+		r.states = states
+	}
+	if r.birth[0] && r.states > 2 {
+		return r, fmt.Errorf("rule %q has B0, which Generations rules can't have", s)
+	}
+
+	return r, nil
+}
+
+// countsRule returns the rule whose cells are born and survive with the numbers of live neighbors listed in birth and
+// survive, separated by commas like 2,3, as an alternative to writing it in B/S notation.
+func countsRule(birth, survive string) (rule, error) {
+	r := rule{states: 2}
+	for _, part := range []struct {
+		name   string
+		list   string
+		counts *[9]bool
+	}{{"birth", birth, &r.birth}, {"survive", survive, &r.survive}} {
+		if part.list == "" {
+			continue
+		}
+		for _, field := range strings.Split(part.list, ",") {
+			count, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || count < 0 || count > 8 {
+				return r, fmt.Errorf("-%s has neighbor count %q, expected 0 to 8", part.name, field)
+			}
+			part.counts[count] = true
+		}
+	}
+
+	return r, nil
+}
+
+// NextState returns the state of a cell in the next generation. A dying cell moves on to its next dying state every
+// generation, whatever its neighbors are doing, until it has gone through all of them and is dead.
+func (r rule) NextState(current, liveNeighbors int) int {
+	switch {
+	case current == 0:
+		if r.birth[liveNeighbors] {
+			return 1
+		}
+		return 0
+	case current == 1:
+		if r.survive[liveNeighbors] {
+			return 1
+		}
+		// Under a Generations rule the cell starts dying rather than being dead right away.
+		if r.states > 2 {
+			return 2
+		}
+		return 0
+	case current+1 < r.states:
+		return current + 1
+	default:
+		return 0
+	}
+}
+
+func (r rule) States() int {
+	return r.states
+}
+
+// String returns the rule in B/S notation, leaving out the number of states when there are no dying states.
+func (r rule) String() string {
+	var b strings.Builder
+	b.WriteString("B")
+	for count, born := range r.birth {
+		if born {
+			b.WriteString(strconv.Itoa(count))
+		}
+	}
+	b.WriteString("/S")
+	for count, survives := range r.survive {
+		if survives {
+			b.WriteString(strconv.Itoa(count))
+		}
+	}
+	if r.states > 2 {
+		fmt.Fprintf(&b, "/%d", r.states)
+	}
+
+	return b.String()
+}
+
+// b0Rule is a rule with B0, under which a dead cell with no live neighbors is born. The endless dead background of a
+// board then comes to life all at once, and unless the rule also has S8 it dies again the generation after, so the
+// whole board flashes and a finite board is swamped by its own edges.
+//
+// Instead the board is stepped by two rules without B0 in turn, the standard way of simulating such rules. Without S8,
+// the cells hold their true states on even generations and their inverted states on odd ones, so the background stays
+// dead throughout and patterns are shown against it rather than flashing with it. With S8 the background never dies
+// again once it has come to life, so the cells hold their inverted states from generation 1 onwards. Generation 0
+// holds the true states the board was seeded with, so the step out of it inverts them like the even steps without S8
+// do, and every step after it goes from inverted states to inverted states.
+type b0Rule struct {
+	rule
+	// even steps the board from even generations but 0, see at, and odd from odd ones.
+	even, odd rule
+}
+
+// newB0Rule returns the rules r is stepped by, see b0Rule.
+func newB0Rule(r rule) b0Rule {
+	if r.survive[8] {
+		inverted := invertOutput(invertInput(r))
+		return b0Rule{rule: r, even: inverted, odd: inverted}
+	}
+	return b0Rule{rule: r, even: invertOutput(r), odd: invertInput(r)}
+}
+
+// at returns the rule which steps the board from generation.
+func (r b0Rule) at(generation int) rule {
+	switch {
+	case generation == 0:
+		return invertOutput(r.rule)
+	case generation%2 == 1:
+		return r.odd
+	}
+	return r.even
+}
+
+// invertOutput returns the rule which gives the inverse of the next state r gives.
+func invertOutput(r rule) rule {
+	for count := range r.birth {
+		r.birth[count], r.survive[count] = !r.birth[count], !r.survive[count]
+	}
+	return r
+}
+
+// invertInput returns the rule which gives the next state r gives to the inverse of the cell and its neighbors: a dead
+// cell with n live neighbors is treated like a live cell with 8-n, and the other way round.
+func invertInput(r rule) rule {
+	inverted := r
+	for count := range r.birth {
+		inverted.birth[count], inverted.survive[count] = r.survive[8-count], r.birth[8-count]
+	}
+	return inverted
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// liveAt returns the live cells of the board as (x, y) pairs, ordered by x and then y.
+func liveAt(b *Board) [][2]int {
+	var live [][2]int
+	for x := range b.cells {
+		for y, c := range b.cells[x] {
+			if c.alive {
+				live = append(live, [2]int{x, y})
+			}
+		}
+	}
+	return live
+}
+
+// offsets returns the cells at the offsets from (x, y), ordered like liveAt.
+func offsets(x, y int, cells ...[2]int) [][2]int {
+	moved := make([][2]int, len(cells))
+	for i, c := range cells {
+		moved[i] = [2]int{x + c[0], y + c[1]}
+	}
+	sort.Slice(moved, func(i, j int) bool {
+		return moved[i][0] < moved[j][0] || moved[i][0] == moved[j][0] && moved[i][1] < moved[j][1]
+	})
+	return moved
+}
+
+// TestDayAndNightOscillator steps the plus sign, a period 4 oscillator of Day & Night, through its phases.
+func TestDayAndNightOscillator(t *testing.T) {
+	phases := [][][2]int{
+		offsets(4, 4, [2]int{0, -1}, [2]int{-1, 0}, [2]int{0, 0}, [2]int{1, 0}, [2]int{0, 1}),
+		offsets(4, 4,
+			[2]int{-1, -1}, [2]int{0, -1}, [2]int{1, -1},
+			[2]int{-1, 0}, [2]int{0, 0}, [2]int{1, 0},
+			[2]int{-1, 1}, [2]int{0, 1}, [2]int{1, 1}),
+		offsets(4, 4,
+			[2]int{0, -2}, [2]int{-1, -1}, [2]int{1, -1},
+			[2]int{-2, 0}, [2]int{0, 0}, [2]int{2, 0},
+			[2]int{-1, 1}, [2]int{1, 1}, [2]int{0, 2}),
+		offsets(4, 4, [2]int{-1, -1}, [2]int{1, -1}, [2]int{0, 0}, [2]int{-1, 1}, [2]int{1, 1}),
+	}
+
+	b := SeededBoard(9, 9, 0, 1)
+	b.rule = DayAndNight{}
+	for _, c := range phases[0] {
+		b.set(c[0], c[1], true)
+	}
+	for generation := 0; generation <= 2*len(phases); generation++ {
+		want := phases[generation%len(phases)]
+		if got := liveAt(b); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("generation %d: got %v, want %v", generation, got, want)
+		}
+		b.Step()
+	}
+}
+
+// TestDayAndNightSymmetric checks that inverting a board and then stepping it gives the same as stepping it and then
+// inverting it.
+func TestDayAndNightSymmetric(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		inverted := SeededBoard(30, 30, 0.4, seed)
+		inverted.rule = DayAndNight{}
+		stepped := SeededBoard(30, 30, 0.4, seed)
+		stepped.rule = DayAndNight{}
+
+		for generation := 0; generation < 10; generation++ {
+			inverted.invert()
+			inverted.Step()
+			stepped.Step()
+			stepped.invert()
+			if inverted.Hash() != stepped.Hash() {
+				t.Fatalf("seed %d generation %d: inverting and stepping differ", seed, generation)
+			}
+		}
+	}
+}
+
+// stepTorus returns the next generation of the cells on a torus following r, worked out directly from its birth and
+// survival counts with nothing like b0Rule in between.
+func stepTorus(r rule, alive [][]bool) [][]bool {
+	columns, rows := len(alive), len(alive[0])
+	next := make([][]bool, columns)
+	for x := range alive {
+		next[x] = make([]bool, rows)
+		for y := range alive[x] {
+			var n int
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					if (dx != 0 || dy != 0) && alive[wrapIndex(x+dx, columns)][wrapIndex(y+dy, rows)] {
+						n++
+					}
+				}
+			}
+			if alive[x][y] {
+				next[x][y] = r.survive[n]
+			} else {
+				next[x][y] = r.birth[n]
+			}
+		}
+	}
+	return next
+}
+
+// TestB0Rules steps rules with B0 on a small torus, with and without S8, and checks every generation against the
+// raw rule stepped directly. Without S8 the board holds the true states on even generations and their inverse on odd
+// ones, with S8 it holds the inverse from generation 1 onwards, see b0Rule.
+func TestB0Rules(t *testing.T) {
+	for _, name := range []string{"B0/S8", "B01/S2", "B02/S013", "B013/S0128", "B013/S1238", "B0123478/S01234678"} {
+		raw, err := parseRule(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := newRule(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := r.(b0Rule); !ok {
+			t.Fatalf("%s isn't simulated as a b0Rule", name)
+		}
+
+		for seed := int64(1); seed <= 3; seed++ {
+			b := SeededBoard(12, 12, 0.4, seed)
+			b.rule = r
+			truth := make([][]bool, len(b.cells))
+			for x := range b.cells {
+				truth[x] = make([]bool, len(b.cells[x]))
+				for y, c := range b.cells[x] {
+					truth[x][y] = c.alive
+				}
+			}
+
+			for generation := 0; generation <= 12; generation++ {
+				inverted := generation%2 == 1
+				if raw.survive[8] {
+					inverted = generation > 0
+				}
+				for x := range b.cells {
+					for y, c := range b.cells[x] {
+						if want := truth[x][y] != inverted; c.alive != want {
+							t.Fatalf("%s seed %d generation %d: cell (%d, %d) is %v, want %v for a true state of %v",
+								name, seed, generation, x, y, c.alive, want, truth[x][y])
+						}
+					}
+				}
+				b.Step()
+				truth = stepTorus(raw, truth)
+			}
+		}
+	}
+}
+
+// TestSeedsDomino steps a domino under Seeds, which makes every live cell die and a dead cell with two live neighbors
+// be born, and checks the cells of the first few generations.
+func TestSeedsDomino(t *testing.T) {
+	want := [][][2]int{
+		{{4, 3}, {4, 5}, {5, 3}, {5, 5}},
+		{{3, 4}, {4, 2}, {4, 6}, {5, 2}, {5, 6}, {6, 4}},
+		{{3, 3}, {3, 5}, {4, 1}, {4, 7}, {5, 1}, {5, 7}, {6, 3}, {6, 5}},
+	}
+
+	parsed, err := newRule("B2/S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []Rule{Seeds{}, parsed} {
+		b := SeededBoard(10, 10, 0, 1)
+		b.rule = r
+		b.set(4, 4, true)
+		b.set(5, 4, true)
+		for generation, cells := range want {
+			b.Step()
+			if got := liveAt(b); fmt.Sprint(got) != fmt.Sprint(cells) {
+				t.Fatalf("%v generation %d: got %v, want %v", r, generation+1, got, cells)
+			}
+		}
+	}
+}
+
+// TestSeedsMatchesParsed checks that the Seeds preset and B2/S parsed from B/S notation run a random board alike.
+func TestSeedsMatchesParsed(t *testing.T) {
+	parsed, err := newRule("B2/S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	preset := SeededBoard(40, 40, 0.1, 7)
+	preset.rule = Seeds{}
+	other := SeededBoard(40, 40, 0.1, 7)
+	other.rule = parsed
+
+	for generation := 1; generation <= 20; generation++ {
+		preset.Step()
+		other.Step()
+		if preset.Hash() != other.Hash() {
+			t.Fatalf("generation %d: Seeds and B2/S differ", generation)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// seedBank is a curated list of seeds read from the -seed-bank file, for showing only starts known to be worth
+// watching. The game starts from a seed picked at random from the bank, and every reset moves on to the next seed in
+// it, starting over from the first after the last.
+type seedBank struct {
+	seeds []int64
+	// The seed to hand out next.
+	next int
+}
+
+// bank is the -seed-bank the seeds are taken from, loaded the first time a seed is needed.
+var bank *seedBank
+
+// loadSeedBank reads a seed bank from path, which holds a seed on each line. Blank lines and lines starting with #
+// are left out, so the seeds can be commented on.
+func loadSeedBank(path string) (*seedBank, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := &seedBank{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		seed, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %q isn't a seed", path, line, text)
+		}
+		s.seeds = append(s.seeds, seed)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.seeds) == 0 {
+		return nil, fmt.Errorf("%s has no seeds in it", path)
+	}
+
+	// Where in the bank the game starts is picked at random, so it doesn't open with the same seed every time.
+	s.next = int(time.Now().UnixNano() % int64(len(s.seeds)))
+	return s, nil
+}
+
+// take returns the next seed in the bank, logging which it is.
+func (s *seedBank) take() int64 {
+	i := s.next
+	s.next = (s.next + 1) % len(s.seeds)
+	logInfof("Seed %d, %d of %d in the seed bank", s.seeds[i], i+1, len(s.seeds))
+
+	return s.seeds[i]
+}
+
+// bankSeed returns the next seed of the -seed-bank, loading it first if it hasn't been yet.
+func bankSeed() int64 {
+	if bank == nil {
+		var err error
+		if bank, err = loadSeedBank(cfg.SeedBank); err != nil {
+			log.Fatalf("-seed-bank: %v", err)
+		}
+	}
+
+	return bank.take()
+}
@@ -0,0 +1,42 @@
+package main
+
+import "math"
+
+// The width in cells of the stripes and of the squares of the checkerboard seeded by -seed-pattern.
+const seedPatternBand = 8
+
+// seedPatterns are the ways the cells can be seeded with -seed-pattern, by name. Each returns the chance of the cell at
+// (x, y) starting out alive, in place of the same -density for every cell. The structured patterns only seed some of
+// the cells, at twice the -density, so that about as many cells start out alive as with uniform.
+var seedPatterns = map[string]func(x, y int) float64{
+	"uniform": func(x, y int) float64 {
+		return cfg.Density
+	},
+	// gaussian is densest in the middle of the board, at twice the -density, thinning out towards the edges like a
+	// bell curve a quarter of the board wide.
+	"gaussian": func(x, y int) float64 {
+		dx := float64(x) - float64(cfg.Columns-1)/2
+		dy := float64(y) - float64(cfg.Rows-1)/2
+		sigma := float64(cfg.Columns+cfg.Rows) / 8
+		return math.Min(1, 2*cfg.Density) * math.Exp(-(dx*dx+dy*dy)/(2*sigma*sigma))
+	},
+	// stripes seeds every other band of columns, leaving the ones in between empty.
+	"stripes": func(x, y int) float64 {
+		if x/seedPatternBand%2 == 1 {
+			return 0
+		}
+		return math.Min(1, 2*cfg.Density)
+	},
+	// checker seeds the black squares of a checkerboard, leaving the white ones empty.
+	"checker": func(x, y int) float64 {
+		if (x/seedPatternBand+y/seedPatternBand)%2 == 1 {
+			return 0
+		}
+		return math.Min(1, 2*cfg.Density)
+	},
+}
+
+// seedPatternNames returns the names of the seed patterns in alphabetical order.
+func seedPatternNames() []string {
+	return sortedNames(seedPatterns)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// defaultShade is the shade function the cells are drawn with when no -cell-shader is given, which leaves their colour
+// as it is.
+const defaultShade = `
+    vec4 shade(vec4 colour, vec2 local) {
+        return colour;
+    }
+`
+
+// cellShade is the shade function read from the -cell-shader file by parseFlags, empty when there is none.
+var cellShade string
+
+// readCellShader reads the shade function from a -cell-shader file, which has to at least mention shade. Whether it
+// defines it as it should is only known once OpenGL compiles and links it, see checkCellShaderLink.
+func readCellShader(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(string(data), "shade") {
+		return "", fmt.Errorf("%s doesn't define vec4 shade(vec4 colour, vec2 local)", path)
+	}
+	return string(data), nil
+}
+
+// shaderError is the error compileShader returns when a shader doesn't compile, with the log OpenGL wrote about it.
+type shaderError struct {
+	source string
+	log    string
+}
+
+func (e *shaderError) Error() string {
+	return fmt.Sprintf("failed to compile %v: %v", e.source, e.log)
+}
+
+// cellFragmentShader returns the source of the fragment shader the cells are drawn with, fragmentShaderSource followed
+// by the definition of its shade function. That is the one read from the -cell-shader file, if there is one, which has
+// to define
+//
+//	vec4 shade(vec4 colour, vec2 local)
+//
+// returning the colour of the fragment at local, its position within its cell between -1 and 1, in a cell of the
+// colour it would otherwise be. It can use anything else GLSL offers a fragment shader, such as gl_FragCoord, and can
+// discard the fragment to leave it out, so cells can glow, be textured or take on any shape.
+func cellFragmentShader() string {
+	shade := defaultShade
+	if cellShade != "" {
+		// The #line directive numbers the lines from the start of the file again, so that the errors OpenGL finds in
+		// it point to the right lines.
+		shade = "#line 1\n" + cellShade + "\n"
+	}
+
+	return fragmentShaderSource + shade + "\x00"
+}
+
+// reportCellShader exits with the log of the errors in the -cell-shader file when err is that it didn't compile. Any
+// other error is left to the caller.
+func reportCellShader(err error) {
+	var compileErr *shaderError
+	if cfg.CellShader != "" && errors.As(err, &compileErr) {
+		log.Fatalf("-cell-shader %s doesn't compile:\n%s", cfg.CellShader, compileErr.log)
+	}
+}
+
+// checkCellShaderLink exits with the log of the errors OpenGL found linking the program the cells are drawn with, when
+// it fails to link with the -cell-shader file, which happens when the file doesn't define shade as it should.
+func checkCellShaderLink(program uint32) {
+	if cfg.CellShader == "" {
+		return
+	}
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		programLog := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(programLog))
+
+		log.Fatalf("-cell-shader %s doesn't link, it has to define vec4 shade(vec4 colour, vec2 local):\n%s",
+			cfg.CellShader, strings.TrimRight(programLog, "\x00"))
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// snapshotCellSize returns the width and height of a cell in a snapshot of a board of columns by rows cells, in pixels,
+// the same size it starts out in the window, or a single pixel when the cells are smaller than that in the window.
+func snapshotCellSize(columns, rows int) int {
+	size := width / columns
+	if height/rows < size {
+		size = height / rows
+	}
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// recordSnapshots writes a PNG image of the board to -pngdir every -snapshot-every generations, if a directory was
+// given, starting after the generations passed over with -skip. Snapshots are named after their generation, so they
+// sort in the order they were taken.
+func recordSnapshots(board *Board) {
+	if cfg.PNGDir == "" {
+		return
+	}
+	if err := os.MkdirAll(cfg.PNGDir, 0o755); err != nil {
+		panic(err)
+	}
+
+	board.OnGeneration(func(b *Board, generation, population int) {
+		if generation <= cfg.Skip || generation%cfg.SnapshotEvery != 0 {
+			return
+		}
+
+		path := filepath.Join(cfg.PNGDir, fmt.Sprintf("generation-%06d.png", generation))
+		if err := writeSnapshot(b, path); err != nil {
+			panic(err)
+		}
+	})
+}
+
+// writeSnapshot writes an image of the board to a PNG file at path.
+func writeSnapshot(b *Board, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, snapshotImage(b)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// snapshotImage draws the board into an image, with the cells in the same colors as in the window on the background
+// color of the theme.
+func snapshotImage(b *Board) *image.RGBA {
+	columns, rows := len(b.cells), len(b.cells[0])
+	size := snapshotCellSize(columns, rows)
+	img := image.NewRGBA(image.Rect(0, 0, columns*size, rows*size))
+	background := currentTheme().background
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = uint8(background[0]*0xff), uint8(background[1]*0xff), uint8(background[2]*0xff)
+		img.Pix[i+3] = 0xff
+	}
+
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			if c.state() == 0 || b.wrapped[c] {
+				continue
+			}
+
+			rgb := cellColor(c, b.rule.States())
+			if b.spaceships[c] {
+				rgb = spaceshipColor
+			}
+			fill := color.RGBA{uint8(rgb[0] * 0xff), uint8(rgb[1] * 0xff), uint8(rgb[2] * 0xff), 0xff}
+			// y grows upwards on the board but downwards in the image, so the image is filled in upside down.
+			top := (rows - 1 - c.y) * size
+			for py := top; py < top+size; py++ {
+				for px := c.x * size; px < (c.x+1)*size; px++ {
+					img.SetRGBA(px, py, fill)
+				}
+			}
+		}
+	}
+
+	return img
+}
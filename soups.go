@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// The most generations a soup is run for. Soups still going by then are reported as lasting at least this long.
+	soupLimit = 10000
+	// The number of longest-lived soups reported.
+	soupTop = 10
+)
+
+// soup is how long the random soup grown from a seed lasted before settling.
+type soup struct {
+	seed int64
+	// lifespan is the generation the board first reached the state it then kept coming back to every period
+	// generations, 1 for a still life.
+	lifespan int
+	period   int
+	settled  bool
+	// The number of live cells once it settled, or when it was given up on.
+	population int
+}
+
+// runSoups runs -soups random soups without opening a window, seeded one after the other from the -seed onwards on
+// boards like the game's own, with its -rule and -boundary, and prints the seeds of the ones which took the longest to
+// settle into still lifes and oscillators. When interrupted it stops early, reporting the soups run so far.
+//
+// With -soup-dir, every soup which lasted at least -soup-lifespan generations or settled with at least
+// -soup-population live cells is saved there as it was seeded, so that it can be looked at more closely.
+func runSoups(interrupt <-chan os.Signal) {
+	if cfg.SoupDir != "" {
+		if err := os.MkdirAll(cfg.SoupDir, 0o755); err != nil {
+			panic(err)
+		}
+	}
+
+	r, err := newRule(paneRules()[0])
+	if err != nil {
+		panic(err)
+	}
+
+	start := newSeed()
+	var soups []soup
+	for seed := start; seed < start+int64(cfg.Soups) && !interrupted(interrupt); seed++ {
+		s := runSoup(r, seed)
+		soups = append(soups, s)
+		if cfg.SoupDir != "" && (s.lifespan >= cfg.SoupLifespan || cfg.SoupPopulation > 0 && s.population >= cfg.SoupPopulation) {
+			saveSoup(r, s)
+		}
+	}
+
+	sort.SliceStable(soups, func(i, j int) bool {
+		return soups[i].lifespan > soups[j].lifespan
+	})
+	if len(soups) > soupTop {
+		soups = soups[:soupTop]
+	}
+	for _, s := range soups {
+		if !s.settled {
+			fmt.Printf("seed %d still going after %d generations\n", s.seed, s.lifespan)
+			continue
+		}
+		fmt.Printf("seed %d settled after %d generations with period %d\n", s.seed, s.lifespan, s.period)
+	}
+}
+
+// runSoup runs the soup grown from seed following the rule until it settles or soupLimit generations have gone by. A board has settled
+// once it comes back to a state it has been in before, from which it can only go round the same cycle forever, which
+// catches oscillators as well as the still lifes Step reports.
+func runSoup(r Rule, seed int64) soup {
+	b := newSeededBoard(r, seed)
+	seen := map[uint64]int{b.Hash(): 0}
+	for b.generation < soupLimit {
+		if !b.Step() {
+			return soup{seed: seed, lifespan: b.generation - 1, period: 1, settled: true, population: b.Population()}
+		}
+
+		h := b.Hash()
+		if first, ok := seen[h]; ok {
+			return soup{seed: seed, lifespan: first, period: b.generation - first, settled: true, population: b.Population()}
+		}
+		seen[h] = b.generation
+	}
+
+	return soup{seed: seed, lifespan: soupLimit, population: b.Population()}
+}
+
+// saveSoup writes the starting state of the soup, which followed the rule, to an RLE file in -soup-dir, named after its
+// seed, lifespan and final population so the most interesting ones stand out in a listing. The rule is in the header
+// and the boundary in a comment, as the flags to run it with, since the soup only settles the same way on the same
+// board.
+func saveSoup(r Rule, s soup) {
+	name := fmt.Sprintf("seed-%d-lifespan-%d-population-%d.rle", s.seed, s.lifespan, s.population)
+	board := fmt.Sprintf("-rule %v -boundary %s", r, cfg.Boundary)
+	if cfg.Wrap != "xy" {
+		board += " -wrap " + cfg.Wrap
+	}
+	if cfg.Edges != "" {
+		board += " -edges " + cfg.Edges
+	}
+	comments := []string{
+		fmt.Sprintf("Random soup from seed %d with density %v", s.seed, cfg.Density),
+		"Run with " + board,
+		fmt.Sprintf("Settled after %d generations with period %d and %d live cells", s.lifespan, s.period, s.population),
+	}
+	if !s.settled {
+		comments[2] = fmt.Sprintf("Still going after %d generations with %d live cells", s.lifespan, s.population)
+	}
+
+	if err := writeRLE(filepath.Join(cfg.SoupDir, name), newSeededBoard(r, s.seed), comments); err != nil {
+		panic(err)
+	}
+	logInfo("Saved", name)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// Spaceships are patterns which come back to their own shape after a few generations, but moved across the board, like
+// the glider. detectSpaceships looks for them by splitting the board into clusters of touching live cells and
+// comparing each cluster to the clusters of spaceshipPeriod generations ago: a cluster with the same shape as one of
+// those, a little way off, is taken to be a spaceship. It is a rough heuristic, which finds the glider and the
+// lightweight spaceship but can be fooled by the debris of a collision.
+
+const (
+	// The number of generations between the two boards compared, the period of both the glider and the lightweight
+	// spaceship.
+	spaceshipPeriod = 4
+	// How far a spaceship can move in spaceshipPeriod generations along x and y. Nothing moves faster than one cell a
+	// generation, and the known small spaceships move much slower.
+	spaceshipReach = 2
+	// Clusters with more cells than this are left alone, they are far more likely to be part of the chaos than a ship.
+	spaceshipMaxCells = 30
+)
+
+// The color cells of detected spaceships are drawn in.
+var spaceshipColor = [3]float32{0.2, 0.8, 1}
+
+// cluster is a group of live cells which touch each other, and its shape: a hash of the positions of its cells
+// relative to the bottom left corner of the rectangle around them.
+type cluster struct {
+	cells            []*cell
+	shape            uint64
+	originX, originY int
+}
+
+// detectSpaceships marks the cells of the spaceships on the board in b.spaceships after every generation, when
+// -spaceships is given.
+func detectSpaceships(board *Board) {
+	if !cfg.Spaceships {
+		return
+	}
+
+	// The clusters of the last spaceshipPeriod generations, by generation modulo spaceshipPeriod. Each slot remembers
+	// the generation it is from, so that a board which is reset isn't compared with its old generations.
+	var history [spaceshipPeriod]struct {
+		generation int
+		clusters   []cluster
+	}
+
+	board.OnGeneration(func(b *Board, generation, population int) {
+		clusters := b.clusters()
+		b.spaceships = make(map[*cell]bool)
+
+		past := history[generation%spaceshipPeriod]
+		if past.generation == generation-spaceshipPeriod {
+			for _, c := range clusters {
+				if b.movedFrom(c, past.clusters) {
+					for _, cell := range c.cells {
+						b.spaceships[cell] = true
+					}
+				}
+			}
+		}
+
+		history[generation%spaceshipPeriod].generation = generation
+		history[generation%spaceshipPeriod].clusters = clusters
+	})
+}
+
+// movedFrom reports whether one of the clusters of the past has the same shape as c, a little way away from it.
+func (b *Board) movedFrom(c cluster, past []cluster) bool {
+	for _, p := range past {
+		if p.shape != c.shape {
+			continue
+		}
+
+		wrapX, wrapY := b.wraps()
+		dx, dy := distance(p.originX, c.originX, len(b.cells), wrapX), distance(p.originY, c.originY, len(b.cells[0]), wrapY)
+		if (dx != 0 || dy != 0) && abs(dx) <= spaceshipReach && abs(dy) <= spaceshipReach {
+			return true
+		}
+	}
+	return false
+}
+
+// distance returns how far it is from one coordinate to another along an axis of the given size, taking the shorter
+// way around the board when it wraps along the axis.
+func distance(from, to, size int, wraps bool) int {
+	d := to - from
+	if wraps {
+		d = ((d % size) + size) % size
+		if d > size/2 {
+			d -= size
+		}
+	}
+	return d
+}
+
+// clusters splits the live cells of the board into clusters of cells touching each other, leaving out the ones too
+// big to be spaceships.
+func (b *Board) clusters() []cluster {
+	var clusters []cluster
+	seen := make(map[*cell]bool)
+	for _, start := range b.liveCells() {
+		if !start.alive || seen[start] {
+			continue
+		}
+
+		// The positions are kept unwrapped, growing past the edges of a board which wraps, so that a cluster lying
+		// across an edge keeps its shape.
+		type position struct{ x, y int }
+		cells := []*cell{start}
+		positions := []position{{start.x, start.y}}
+		seen[start] = true
+		for i := 0; i < len(cells); i++ {
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					x, y := positions[i].x+dx, positions[i].y+dy
+					if n := b.at(x, y); n != nil && n.alive && !seen[n] {
+						seen[n] = true
+						cells = append(cells, n)
+						positions = append(positions, position{x, y})
+					}
+				}
+			}
+		}
+		if len(cells) > spaceshipMaxCells {
+			continue
+		}
+
+		minX, minY := positions[0].x, positions[0].y
+		for _, p := range positions {
+			if p.x < minX {
+				minX = p.x
+			}
+			if p.y < minY {
+				minY = p.y
+			}
+		}
+		sort.Slice(positions, func(i, j int) bool {
+			return positions[i].x < positions[j].x || positions[i].x == positions[j].x && positions[i].y < positions[j].y
+		})
+		h := fnv.New64a()
+		for _, p := range positions {
+			h.Write([]byte{byte(p.x - minX), byte(p.y - minY)})
+		}
+
+		clusters = append(clusters, cluster{cells: cells, shape: h.Sum64(), originX: minX, originY: minY})
+	}
+
+	return clusters
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
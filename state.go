@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A .gol file saved with -save holds the state of a board compactly, to be loaded again with -load and carried on
+// from where it was. It starts with stateMagic and a little endian uint16 giving the version of the format, followed
+// by the version's header and the cells packed into bits like a frame of a recording.
+//
+// The header of version 1 is the number of columns and rows of the board as uint32s, the generation as a uint64 and
+// the rule in B/S notation as a uint16 length followed by its bytes, all little endian. Only whether each cell is
+// alive is kept, so the dying cells of a Generations rule are dead once loaded.
+
+// The bytes a saved board starts with, which tell it apart from any other file.
+const stateMagic = "LIFEGOL1"
+
+// The version of the format written by saveState. A newer version may add to the header, which loadState can't know
+// how to read, so it refuses files with a version newer than this.
+const stateVersion = 1
+
+// stateHeader is the header of a saved board in version 1 of the format, without its rule, whose length varies.
+type stateHeader struct {
+	Columns    uint32
+	Rows       uint32
+	Generation uint64
+}
+
+// saveState saves the board to a .gol file at path.
+func saveState(b *Board, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+
+	columns, rows := len(b.cells), len(b.cells[0])
+	rule := fmt.Sprint(b.rule)
+	w.WriteString(stateMagic)
+	binary.Write(w, binary.LittleEndian, uint16(stateVersion))
+	binary.Write(w, binary.LittleEndian, stateHeader{Columns: uint32(columns), Rows: uint32(rows), Generation: uint64(b.generation)})
+	binary.Write(w, binary.LittleEndian, uint16(len(rule)))
+	w.WriteString(rule)
+	frame := make([]byte, frameSize(columns, rows))
+	packCells(b, frame)
+	w.Write(frame)
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readStateHeader reads the magic, version and header of the .gol file at path from r, up to its rule.
+func readStateHeader(r io.Reader, path string) (stateHeader, error) {
+	var header stateHeader
+	magic := make([]byte, len(stateMagic))
+	var version uint16
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != stateMagic {
+		return header, fmt.Errorf("%s isn't a board saved with -save", path)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return header, fmt.Errorf("%s: %v", path, err)
+	}
+	// Versions are numbered from 1, so 0 is no version at all.
+	if version == 0 {
+		return header, fmt.Errorf("%s has no version of the format", path)
+	}
+	if version > stateVersion {
+		return header, fmt.Errorf("%s was saved in version %d of the format, this only reads up to version %d", path, version, stateVersion)
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return header, fmt.Errorf("%s: %v", path, err)
+	}
+	return header, nil
+}
+
+// stateSize returns the number of columns and rows of the board saved to the .gol file at path, so that the board
+// can be made that size before it is loaded.
+func stateSize(path string) (columns, rows int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	header, err := readStateHeader(bufio.NewReader(f), path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(header.Columns), int(header.Rows), nil
+}
+
+// loadState loads the board with the cells, generation and rule saved to the .gol file at path, which has to be of a
+// board of the same size, see stateSize.
+func (b *Board) loadState(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	header, err := readStateHeader(r, path)
+	if err != nil {
+		return err
+	}
+	var ruleLength uint16
+	if err := binary.Read(r, binary.LittleEndian, &ruleLength); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	ruleText := make([]byte, ruleLength)
+	if _, err := io.ReadFull(r, ruleText); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	columns, rows := len(b.cells), len(b.cells[0])
+	if int(header.Columns) != columns || int(header.Rows) != rows {
+		return fmt.Errorf("%s is of a board of %d by %d cells, expected %d by %d", path, header.Columns, header.Rows, columns, rows)
+	}
+	rule, err := newRule(string(ruleText))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	frame := make([]byte, frameSize(columns, rows))
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	unpackCells(b, frame)
+	b.rule = rule
+	b.generation = int(header.Generation)
+	b.births, b.deaths = 0, 0
+	b.unchanged = false
+	return nil
+}
+
+// saveOnExit saves the board to the -save file, if there is one, to be deferred until the game ends.
+func saveOnExit(b *Board) {
+	if cfg.Save == "" {
+		return
+	}
+
+	if err := saveState(b, cfg.Save); err != nil {
+		panic(err)
+	}
+	logInfo("Saved generation", b.generation, "to", cfg.Save)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStateRoundTrip saves a board, loads it into a fresh board of the same size and checks that it carries on from
+// the same rule, generation and cells, and that saving it again gives the very same file.
+func TestStateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	b := SeededBoard(30, 40, 0.3, 9)
+	b.rule = HighLife{}
+	for i := 0; i < 7; i++ {
+		b.Step()
+	}
+	first := filepath.Join(dir, "first.gol")
+	if err := saveState(b, first); err != nil {
+		t.Fatal(err)
+	}
+
+	if columns, rows, err := stateSize(first); err != nil || columns != 40 || rows != 30 {
+		t.Fatalf("stateSize gives %d by %d, %v, want 40 by 30", columns, rows, err)
+	}
+	loaded := SeededBoard(30, 40, 0.5, 1)
+	if err := loaded.loadState(first); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loaded.rule) != fmt.Sprint(b.rule) || loaded.generation != b.generation || loaded.Hash() != b.Hash() {
+		t.Fatalf("loaded rule %v, generation %d, hash %x, want %v, %d, %x",
+			loaded.rule, loaded.generation, loaded.Hash(), b.rule, b.generation, b.Hash())
+	}
+
+	second := filepath.Join(dir, "second.gol")
+	if err := saveState(loaded, second); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := os.ReadFile(first)
+	got, _ := os.ReadFile(second)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("saving the loaded board again gives %d different bytes from the %d first saved", len(got), len(want))
+	}
+}
+
+// TestStateRejects checks that loadState refuses files which aren't boards it can load, leaving the board alone.
+func TestStateRejects(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "board.gol")
+	if err := saveState(SeededBoard(10, 12, 0.3, 2), path); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := os.ReadFile(path)
+	withVersion := func(version uint16) []byte {
+		data := append([]byte(nil), saved...)
+		binary.LittleEndian.PutUint16(data[len(stateMagic):], version)
+		return data
+	}
+
+	for _, tc := range []struct {
+		name          string
+		data          []byte
+		columns, rows int
+		err           string
+	}{
+		{"bad magic", append([]byte("LIFEGOL0"), saved[len(stateMagic):]...), 12, 10, "isn't a board saved with -save"},
+		{"version 0", withVersion(0), 12, 10, "has no version"},
+		{"newer version", withVersion(stateVersion + 1), 12, 10, "only reads up to version"},
+		{"other size", saved, 10, 12, "is of a board of 12 by 10 cells, expected 10 by 12"},
+	} {
+		if err := os.WriteFile(path, tc.data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		b := SeededBoard(tc.rows, tc.columns, 0.3, 5)
+		hash := b.Hash()
+		err := b.loadState(path)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got error %v, want one saying %q", tc.name, err, tc.err)
+		}
+		if b.Hash() != hash || b.generation != 0 {
+			t.Errorf("%s: the board was changed", tc.name)
+		}
+	}
+}
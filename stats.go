@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+)
+
+// How many generations of statistics are buffered before they are flushed to the file.
+const statsFlushInterval = 100
+
+// statsWriter writes a row of statistics about the board to a CSV file for every generation.
+// A nil *statsWriter discards everything, so callers don't have to check whether -csv was given.
+type statsWriter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// newStatsWriter creates the CSV file at path, replacing any existing file, and writes its header.
+func newStatsWriter(path string) (*statsWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &statsWriter{f: f, w: csv.NewWriter(f)}
+	if err := s.w.Write([]string{"generation", "population", "births", "deaths"}); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// write adds a row for a generation.
+func (s *statsWriter) write(generation, population, births, deaths int) error {
+	if s == nil {
+		return nil
+	}
+
+	err := s.w.Write([]string{
+		strconv.Itoa(generation),
+		strconv.Itoa(population),
+		strconv.Itoa(births),
+		strconv.Itoa(deaths),
+	})
+	if err != nil {
+		return err
+	}
+
+	if generation%statsFlushInterval == 0 {
+		s.w.Flush()
+		return s.w.Error()
+	}
+	return nil
+}
+
+// Close flushes any buffered rows and closes the file.
+func (s *statsWriter) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
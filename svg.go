@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"os"
+)
+
+// writeSVG writes the board to an SVG file at path, with every live or dying cell a one by one rect on a grid the
+// size of the board, so it scales to any size without blurring. The cells are in the same colors as in the window, and
+// the generation is given as the title of the image.
+func writeSVG(b *Board, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	columns, rows := len(b.cells), len(b.cells[0])
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 %d %d\" shape-rendering=\"crispEdges\">\n", columns, rows)
+	fmt.Fprintf(w, "  <title>%s, generation %d</title>\n", html.EscapeString(cfg.Title), b.generation)
+	fmt.Fprintf(w, "  <rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", columns, rows, svgColor(currentTheme().background))
+	for _, c := range b.liveCells() {
+		rgb := cellColor(c, b.rule.States())
+		if b.spaceships[c] {
+			rgb = spaceshipColor
+		}
+		// y grows upwards on the board but downwards in SVG, so the image is written upside down.
+		fmt.Fprintf(w, "  <rect x=\"%d\" y=\"%d\" width=\"1\" height=\"1\" fill=\"%s\"/>\n", c.x, rows-1-c.y, svgColor(rgb))
+	}
+	fmt.Fprintln(w, "</svg>")
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// svgColor returns an RGB color as an SVG color, like #ffffff for white.
+func svgColor(rgb [3]float32) string {
+	return fmt.Sprintf("#%02x%02x%02x", uint8(rgb[0]*0xff), uint8(rgb[1]*0xff), uint8(rgb[2]*0xff))
+}
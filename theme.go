@@ -0,0 +1,52 @@
+package main
+
+// theme is a set of colors the board is drawn in, chosen together with -theme so that they go well with each other.
+type theme struct {
+	// The color of the window behind the cells.
+	background [3]float32
+	// The color of a live cell.
+	cell [3]float32
+	// The color a cell fades towards as it dies under a Generations rule, reached in its last dying state.
+	faded [3]float32
+}
+
+// themes are the themes that can be chosen with -theme, by name.
+var themes = map[string]theme{
+	// classic is white cells on black, fading to a dim blue.
+	"classic": {
+		background: [3]float32{0, 0, 0},
+		cell:       [3]float32{1, 1, 1},
+		faded:      [3]float32{0.2, 0.2, 0.5},
+	},
+	// mono is black cells on white, like a drawing on paper.
+	"mono": {
+		background: [3]float32{1, 1, 1},
+		cell:       [3]float32{0, 0, 0},
+		faded:      [3]float32{0.75, 0.75, 0.75},
+	},
+	"matrix": {
+		background: [3]float32{0, 0.05, 0},
+		cell:       [3]float32{0.3, 1, 0.3},
+		faded:      [3]float32{0, 0.3, 0.05},
+	},
+	"fire": {
+		background: [3]float32{0.08, 0, 0},
+		cell:       [3]float32{1, 0.9, 0.3},
+		faded:      [3]float32{0.6, 0.05, 0},
+	},
+	"ice": {
+		background: [3]float32{0, 0.03, 0.1},
+		cell:       [3]float32{0.85, 0.95, 1},
+		faded:      [3]float32{0.1, 0.3, 0.6},
+	},
+}
+
+// currentTheme returns the theme chosen with -theme.
+func currentTheme() theme {
+	return themes[cfg.Theme]
+}
+
+// themeNames returns the names of the themes in alphabetical order.
+func themeNames() []string {
+	return sortedNames(themes)
+}
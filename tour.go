@@ -0,0 +1,30 @@
+package main
+
+// tour shows the patterns -place knows one after the other, each on an empty board, for showing off the classics
+// without giving any flags. Tab moves on to the next one.
+type tour struct {
+	// The index of the pattern shown last, in alphabetical order, -1 before the first.
+	current int
+}
+
+// newTour returns a tour which starts with the first pattern.
+func newTour() *tour {
+	return &tour{current: -1}
+}
+
+// next clears the board and puts the next pattern in the middle of it, starting over from the first after the last,
+// and returns its name.
+func (t *tour) next(b *Board) string {
+	names := sortedNames(patterns)
+	t.current = (t.current + 1) % len(names)
+	name := names[t.current]
+
+	p, colors, err := parsePlaintext(patterns[name])
+	if err != nil {
+		panic(err)
+	}
+	b.clear()
+	left, top := center(p, b.cells)
+	stamp(b.cells, p, colors, left, top)
+	return name
+}
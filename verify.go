@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// verifyOrder checks that the next generation of the board doesn't depend on the order its cells are visited in, by
+// working it out both column by column and row by row and comparing the two. Every cell has to decide its next state
+// from the current states of its neighbors, so a difference means some cell saw a neighbor's next state instead.
+func (b *Board) verifyOrder() error {
+	byColumn := b.nextStates(false)
+	byRow := b.nextStates(true)
+	for i := range byColumn {
+		if byColumn[i] != byRow[i] {
+			x, y := i/len(b.cells[0]), i%len(b.cells[0])
+			return fmt.Errorf("generation %d: cell (%d, %d) becomes %d column by column but %d row by row",
+				b.generation+1, x, y, byColumn[i], byRow[i])
+		}
+	}
+
+	return nil
+}
+
+// nextStates works out the next state of every cell, visiting the cells row by row or column by column, and returns
+// them column by column. The cells are left with their next states worked out, but not applied.
+func (b *Board) nextStates(byRow bool) []int {
+	columns, rows := len(b.cells), len(b.cells[0])
+	for i := 0; i < columns*rows; i++ {
+		x, y := i/rows, i%rows
+		if byRow {
+			x, y = i%columns, i/columns
+		}
+		c := b.cells[x][y]
+		c.checkState(b.rule, b.countNeighbors(c))
+	}
+
+	states := make([]int, 0, columns*rows)
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			switch {
+			case c.aliveNext:
+				states = append(states, 1)
+			case c.dyingNext > 0:
+				states = append(states, int(c.dyingNext)+1)
+			default:
+				states = append(states, 0)
+			}
+		}
+	}
+
+	return states
+}
@@ -0,0 +1,58 @@
+package main
+
+// detectWrapped marks the cells on the board which just came to life across an edge from the cells which brought them
+// to life in b.wrapped after every generation, when -hide-wrapped is given. Those are the cells a spaceship leaving the
+// board by one edge first comes back with by the opposite one, seemingly out of nowhere, so they are left out when
+// the board is drawn. The board itself still wraps as it always does, and the cells are drawn from the next
+// generation on.
+func detectWrapped(board *Board) {
+	if !cfg.HideWrapped {
+		return
+	}
+
+	// Which cells were alive in the generation before, indexed by x*rows+y, and which generation that was, so that a
+	// board which is reset isn't compared with its old generations.
+	columns, rows := len(board.cells), len(board.cells[0])
+	was := make([]bool, columns*rows)
+	last := board.generation
+	remember := func(b *Board) {
+		for x := range b.cells {
+			for y, c := range b.cells[x] {
+				was[x*rows+y] = c.alive
+			}
+		}
+	}
+	remember(board)
+
+	board.OnGeneration(func(b *Board, generation, population int) {
+		b.wrapped = make(map[*cell]bool)
+		if generation == last+1 {
+			for _, c := range b.liveCells() {
+				if c.alive && !was[c.x*rows+c.y] && b.bornAcrossEdge(c, was) {
+					b.wrapped[c] = true
+				}
+			}
+		}
+		remember(b)
+		last = generation
+	})
+}
+
+// bornAcrossEdge reports whether all of the neighbors of the cell which were alive in the generation before, as given
+// by was, are across an edge of the board from it.
+func (b *Board) bornAcrossEdge(c *cell, was []bool) bool {
+	rows := len(b.cells[0])
+	var parents int
+	for _, n := range c.neighbors {
+		if n == outside || n == aliveOutside || !was[n.x*rows+n.y] {
+			continue
+		}
+		// Neighbors on the same side of the edges are never more than a cell away.
+		if abs(n.x-c.x) <= 1 && abs(n.y-c.y) <= 1 {
+			return false
+		}
+		parents++
+	}
+
+	return parents > 0
+}